@@ -0,0 +1,64 @@
+package caller
+
+// This file contains the helpers that describe the caller as attributes for structured logging and tracing.
+
+import "strconv"
+
+// The OpenTelemetry semantic convention keys for the source code attributes.
+const (
+	OTelCodeFunction  = "code.function"
+	OTelCodeFilepath  = "code.filepath"
+	OTelCodeLineno    = "code.lineno"
+	OTelCodeNamespace = "code.namespace"
+)
+
+// OTelCodeAttributes returns the caller as the OpenTelemetry source code attributes; code.function,
+// code.filepath, code.lineno and code.namespace. The namespace is the package, followed by the receiver type
+// for methods. An empty map is returned if the caller could not be found.
+func (c ACaller) OTelCodeAttributes() map[string]string {
+	frame, ok := c.caller(5)
+	if !ok {
+		return map[string]string{}
+	}
+	info := frameInfo(frame)
+	namespace := info.Package
+	if info.Receiver != "" {
+		namespace += "." + info.Receiver
+	}
+	return map[string]string{
+		OTelCodeFunction:  info.Function,
+		OTelCodeFilepath:  info.File,
+		OTelCodeLineno:    strconv.Itoa(info.Line),
+		OTelCodeNamespace: namespace,
+	}
+}
+
+// CallerFields returns the caller as fields for structured loggers, such as zap or zerolog; "caller" is the
+// file:line of the caller, and "func" is the full function name. An empty map is returned if the caller could
+// not be found.
+func (c ACaller) CallerFields() map[string]interface{} {
+	frame, ok := c.caller(5)
+	if !ok {
+		return map[string]interface{}{}
+	}
+	return map[string]interface{}{
+		"caller": frameFileLine(frame),
+		"func":   frame.Function,
+	}
+}
+
+// CallerRecordPC returns the program counter of the caller, in the form stored in the PC field of a log/slog
+// Record; so the source of the record is the caller, honoring the ignore lists. 0 is returned if there is no
+// caller, which slog treats as having no source.
+func (c ACaller) CallerRecordPC() uintptr {
+	frame, ok := c.caller(5)
+	if !ok {
+		return 0
+	}
+	// slog, like runtime.Callers, expects the return address; one passed the call instruction
+	return frame.PC + 1
+}
+
+// Copyright 2021 Gautam Dey. All rights reserved.
+// Use of this source code is governed by the MIT
+// license that can be found in the LICENSE FILE
@@ -0,0 +1,59 @@
+package caller_test
+
+import (
+	"reflect"
+	"runtime"
+	"strconv"
+	"testing"
+
+	"github.com/gdey/caller"
+)
+
+type attrsLog struct{ c caller.ACaller }
+
+func otelAttributesOf(c caller.ACaller) map[string]string { return c.OTelCodeAttributes() }
+
+func (l attrsLog) Span() map[string]string { return otelAttributesOf(l.c) }
+
+func TestACaller_OTelCodeAttributes(t *testing.T) {
+	var l attrsLog
+	attrs := l.Span()
+	if attrs["code.function"] != "Span" || attrs["code.namespace"] != "github.com/gdey/caller_test.attrsLog" {
+		t.Errorf("attributes expected the method Span of attrsLog got %v", attrs)
+	}
+
+	l.c.IgnoreFunction("attrsLog.Span")
+	_, file, line, _ := runtime.Caller(0)
+	attrs = l.Span()
+	expected := map[string]string{
+		"code.function":  "TestACaller_OTelCodeAttributes",
+		"code.filepath":  file,
+		"code.lineno":    strconv.Itoa(line + 1),
+		"code.namespace": "github.com/gdey/caller_test",
+	}
+	if !reflect.DeepEqual(attrs, expected) {
+		t.Errorf("attributes expected %v got %v", expected, attrs)
+	}
+}
+
+func fieldsOf(c caller.ACaller) map[string]interface{} { return c.CallerFields() }
+
+func TestACaller_CallerFields(t *testing.T) {
+	var c caller.ACaller
+	_, file, line, _ := runtime.Caller(0)
+	fields := fieldsOf(c)
+	expected := map[string]interface{}{
+		"caller": file + ":" + strconv.Itoa(line+1),
+		"func":   "github.com/gdey/caller_test.TestACaller_CallerFields",
+	}
+	if !reflect.DeepEqual(fields, expected) {
+		t.Errorf("fields expected %v got %v", expected, fields)
+	}
+
+	c.IgnorePackage()
+	done := make(chan map[string]interface{})
+	go func() { done <- fieldsOf(c) }()
+	if fields := <-done; len(fields) != 0 {
+		t.Errorf("fields expected to be empty got %v", fields)
+	}
+}
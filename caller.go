@@ -6,21 +6,36 @@ package caller
 // This file contains the implementation of the caller helper functions and data structure.
 
 import (
+	"bufio"
+	"errors"
+	"io"
+	"path"
+	"regexp"
 	"runtime"
+	"strconv"
 	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+	"unicode"
+	"unicode/utf8"
 )
 
 const (
-	// DefaultNumberOfFramesToGet is the number of frame we attempt to retrieve from the runtime to determine the
-	// calling function. This value has been good enough for my tests, but may not be sufficient for very deep call
-	// stacks. This value can be changed via the SetNumberOfFramesToGet function.
+	// DefaultNumberOfFramesToGet is the number of frame we attempt to retrieve from the runtime, at a time, to
+	// determine the calling function. This value has been good enough for my tests; deeper stacks are retrieved in
+	// more chunks. This value can be changed via the SetNumberOfFramesToGet function.
 	DefaultNumberOfFramesToGet = 15
 )
 
-var defaultCaller ACaller
+var defaultCaller = ACaller{growRetries: new(uint64)}
 
 // PackageName will parse the full function name provided by a frame to find the package name
 func PackageName(fullFuncName string) string {
+	// packages instrumented by go test have the form "pkg [pkg.test]"
+	if idx := strings.Index(fullFuncName, "]."); idx != -1 && strings.Contains(fullFuncName[:idx], " [") {
+		return fullFuncName[:idx+1]
+	}
 	// we need to see if the name has a '/' in it; if so, we will need to
 	// find the '.' after the last '/', if not then the first '.' is the
 	// package separator
@@ -60,50 +75,355 @@ func ourPackage() (packageName string) {
 	return packageName
 }
 
+var (
+	// ErrNoCallers is returned when there are no frames, outside of the ignore lists, on the stack.
+	ErrNoCallers = errors.New("caller: no callers")
+	// ErrNotEnoughFrames is returned when the frames ran out before a caller, or the bottom of the stack, was found.
+	// As the whole stack is walked, this only happens for a stack that does not end in runtime.goexit or
+	// runtime.main; such as a stack that was truncated by the runtime.
+	ErrNotEnoughFrames = errors.New("caller: not enough frames")
+)
+
 // getFrames will attempt retrieve the (num + skip) number of frames; then then skip passed the 'skip' number of frame.
+// The skip values used in this package assume runtime.Callers reports one frame (it's own) before the frame of
+// getFrames; if that is not the case the skip is adjusted by the internal skip base. If the frames could not be
+// retrieved there are no frames, see getFramesErr.
 func getFrames(num int, skip int) *runtime.Frames {
+	// account for our own frame
+	frames, err := getFramesErr(num, skip+1)
+	if err != nil {
+		return runtime.CallersFrames(nil)
+	}
+	return frames
+}
+
+// getFramesErr is like getFrames, but returns ErrNoCallers if the runtime returned no frames, and
+// ErrNotEnoughFrames if there are fewer frames then skip.
+func getFramesErr(num int, skip int) (*runtime.Frames, error) {
+	// account for our own frame
+	pc, err := capturePCs(num, skip+1)
+	if err != nil {
+		return nil, err
+	}
+	return runtime.CallersFrames(pc), nil
+}
+
+// capturePCs returns the program counters for getFramesErr; the skip is the same as for getFrames.
+func capturePCs(num int, skip int) ([]uintptr, error) {
+	skip += int(atomic.LoadInt32(&internalSkipBase)) - 1
+	if skip < 0 {
+		skip = 0
+	}
 	// Ask runtime.Callers for up to 10 pcs, including runtime.Callers itself.
 	pc := make([]uintptr, num+skip)
 	n := runtime.Callers(0, pc)
 	if n == 0 {
 		// No pcs available. Stop now.
 		// This can happen if the first argument to runtime.Callers is large.
-		panic("no callers")
+		return nil, ErrNoCallers
+	}
+	if skip >= n {
+		return nil, ErrNotEnoughFrames
+	}
+
+	return pc[skip:n], nil // pass only valid pcs to runtime.CallersFrames
+}
+
+// internalSkipBase is the number of frames runtime.Callers(0, ...) reports before the frame of the function
+// that called it. It is detected when the package is initialized.
+var internalSkipBase = detectInternalSkipBase()
+
+// detectInternalSkipBase finds the number of frames runtime.Callers reports before the frame of it's caller;
+// by looking for the frame of this function.
+func detectInternalSkipBase() int32 {
+	pc := make([]uintptr, 8)
+	n := runtime.Callers(0, pc)
+	frames := runtime.CallersFrames(pc[:n])
+	for i := int32(0); ; i++ {
+		frame, more := frames.Next()
+		if strings.HasSuffix(frame.Function, ".detectInternalSkipBase") {
+			return i
+		}
+		if !more {
+			// should not happen, so use the value for the current runtime
+			return 1
+		}
+	}
+}
+
+// SetInternalSkipBase changes the number of frames runtime.Callers is assumed to report before the frame of the
+// function that called it. This is detected when the package is initialized, and should not need to be changed;
+// but allows adjusting for changes to the runtime without needing a new version of this package. A negative
+// value will redo the detection.
+func SetInternalSkipBase(n int) {
+	if n < 0 {
+		n = int(detectInternalSkipBase())
+	}
+	atomic.StoreInt32(&internalSkipBase, int32(n))
+}
+
+// InternalSkipBase returns the number of frames runtime.Callers is assumed to report before the frame of the
+// function that called it.
+func InternalSkipBase() int { return int(atomic.LoadInt32(&internalSkipBase)) }
+
+// fallbackPackageName is the name of this package, used if it could not be found by walking the frames
+const fallbackPackageName = "github.com/gdey/caller"
+
+// ourPackageName is the name of this package, and selfResolvedFromFrames is weather it was found by walking the
+// frames rather then being the fallback.
+var ourPackageName, selfResolvedFromFrames = resolveOurPackage(ourPackage)
+
+// resolveOurPackage returns the package name found by walk, and true; or the fallback package name and false if
+// walk could not find it.
+func resolveOurPackage(walk func() string) (packageName string, fromFrames bool) {
+	packageName = walk()
+	if packageName == "" || packageName == "runtime" {
+		return fallbackPackageName, false
+	}
+	return packageName, true
+}
+
+// SelfPackageResolvedFromFrames returns weather the name of this package was found by walking the frames (true),
+// or the name it is published under was used as a fallback (false). The fallback means the frames are not what
+// this package expects, and the ignore lists may not work as expected.
+func SelfPackageResolvedFromFrames() bool { return selfResolvedFromFrames }
+
+// selfAliases are the other package names that are treated as this package; it holds a []string
+var selfAliases atomic.Value
+
+// isSelfPackage returns weather the package is this package, or one of it's aliases
+func isSelfPackage(packageName string) bool {
+	if packageName == ourPackageName {
+		return true
 	}
-	if skip >= (n - 1) {
-		panic("not enough frames")
+	aliases, _ := selfAliases.Load().([]string)
+	for _, alias := range aliases {
+		if packageName == alias {
+			return true
+		}
 	}
+	return false
+}
+
+// aliasLock serializes the updates to selfAliases
+var aliasLock sync.Mutex
 
-	pc = pc[skip:n] // pass only valid pcs to runtime.CallersFrames
-	return runtime.CallersFrames(pc)
+// AddSelfPackageAlias adds another package name that should be treated as this package; frames in it are
+// always skipped. This is for when the source of this package is copied into another module, such as a fork, and
+// both packages end up in the same binary.
+func AddSelfPackageAlias(pkg string) {
+	aliasLock.Lock()
+	defer aliasLock.Unlock()
+	aliases, _ := selfAliases.Load().([]string)
+	for _, alias := range aliases {
+		if alias == pkg {
+			return
+		}
+	}
+	// copy the aliases, as readers may still be using the old slice
+	selfAliases.Store(append(append([]string(nil), aliases...), pkg))
+	resetClassCache()
 }
 
-//var ourPackageName = "github.com/gdey/caller"
-var ourPackageName = ourPackage()
+// RemoveSelfPackageAlias removes a package name added with AddSelfPackageAlias; frames in it are no longer
+// skipped as this package. It returns weather the package was an alias.
+func RemoveSelfPackageAlias(pkg string) bool {
+	aliasLock.Lock()
+	defer aliasLock.Unlock()
+	aliases, _ := selfAliases.Load().([]string)
+	// removeName copies the aliases, as readers may still be using the old slice
+	aliases, removed := removeName(aliases, pkg)
+	if !removed {
+		return false
+	}
+	selfAliases.Store(aliases)
+	resetClassCache()
+	return true
+}
 
 type ACaller struct {
 	// numFramesToGet is the number of frame we should get; if this values is 0 or less it will default
 	// to the default value
 	numFramesToGet int
+	// growRetries if not nil is the number of extra chunks of frames retrieved from the runtime; see GrowRetries
+	growRetries *uint64
 	// ignorePackages is the list of packages to ignore when walking the stack
 	ignorePackages []string
 	// ignoreFunctions is the list of functions to ignore when walking the stack
 	ignoreFunctions []string
+	// ignorePackageSet and ignoreFunctionSet are the entries of the ignore lists, for the lookups made for every
+	// frame, and ignorePackagePatterns are the entries of the package ignore list that are glob patterns; see
+	// indexIgnoreLists
+	ignorePackageSet      nameSet
+	ignoreFunctionSet     nameSet
+	ignorePackagePatterns []string
+	// ignoreReasons is the reason, keyed by function name, a function was added to the ignore list
+	ignoreReasons map[string]string
+	// allowedModules if not empty is the list of package prefixes a frame must have to not be skipped
+	allowedModules []string
+	// skipCompilerWrappers if true will skip frames of wrapper functions generated by the compiler
+	skipCompilerWrappers bool
+	// ignoreForwarders if true will skip frames that look like they only forward the call
+	ignoreForwarders bool
+	// usage if not nil tracks which of the ignore entries are matching frames
+	usage *ignoreUsage
+	// unknownName is the placeholder used by the string helpers when the caller could not be found
+	unknownName string
+	// anchor is the full function name of the anchor function, see SetAnchor
+	anchor string
+	// clock if not nil is used in place of time.Now by the time based features; it allows tests to control time
+	clock func() time.Time
+	// filterMode is the order the allowed modules and the ignore lists are applied in
+	filterMode FilterMode
+	// unifyMethodForms if true treats the different forms of a method's name as the same name
+	unifyMethodForms bool
+	// canonicalizer if not nil is used to normalize the names in the ignore lists and the names of the frames
+	// before they are compared
+	canonicalizer func(string) string
+	// infoFilters are the filters, given the parsed frame, that decide if a frame should be skipped; see AddInfoFilter
+	infoFilters []func(CallerInfo) bool
+	// checkOrder if not empty is the order the categories of the ignore rules are checked in; see SetCheckOrder
+	checkOrder []CheckKind
+	// attributeClosuresToParent if true reports closures as the named function they are declared in
+	attributeClosuresToParent bool
+	// includeSelf if true will not skip the frames of this package; see SetIncludeSelf
+	includeSelf bool
+	// sourceReadLimit if greater then 0 is the most bytes read from a source file; see SetSourceReadLimit
+	sourceReadLimit int64
+	// preferTestFunction if true will prefer the nearest test function as the caller; see SetPreferTestFunction
+	preferTestFunction bool
+	// ignoreFileExtensions are the endings of the file names whose frames are skipped; see IgnoreFileExtensions
+	ignoreFileExtensions []string
+	// packageLevels is the suggested log level, keyed by package; see SetPackageLevel
+	packageLevels map[string]string
+	// includeRuntime if true will not skip the frames of the runtime package; see SetSkipRuntime
+	includeRuntime bool
+	// callersDepth if greater then 0 is the most frames returned by Callers; see SetCallersDepth
+	callersDepth int
+	// ignorePackagePrefixes is the list of prefixes of the packages to ignore; see IgnorePackagePrefix
+	ignorePackagePrefixes []string
 }
 
-// IgnorePackage will mark the calling functions package as a package to ignore when
-// the ACaller function is called in the search for the caller
-//
-// Note this should be called prior to any functions in the package calling the Helper() methods
-// as that functions to it's ignore list who's package is not in the package list. This decreases the amount
-// of memory used by the ACaller structure, as the Caller method always scans the the package ignore list first
-// before scanning the function ignore list.
-func (c *ACaller) IgnorePackage() {
+// now returns the current time, as given by the clock; any time based feature should use this, rather than
+// time.Now, so it can be tested deterministically.
+func (c ACaller) now() time.Time {
+	if c.clock == nil {
+		return time.Now()
+	}
+	return c.clock()
+}
+
+// setClockForTest replaces the clock used by the time based features; a nil fn restores time.Now.
+func (c *ACaller) setClockForTest(fn func() time.Time) { c.clock = fn }
+
+// canonicalName will run the name through the configured canonicalizer, if there is one; and unify the
+// method forms if configured to.
+func (c ACaller) canonicalName(name string) string {
+	if c.canonicalizer != nil {
+		name = c.canonicalizer(name)
+	}
+	if c.unifyMethodForms {
+		name = unifyMethodName(name)
+	}
+	return name
+}
+
+// recanonicalize runs the entries of the ignore lists, and the other configured names, through canonicalName. New
+// lists are built, as the old ones may be shared with copies of the caller.
+func (c *ACaller) recanonicalize() {
+	c.ignorePackages = c.canonicalNames(c.ignorePackages)
+	c.ignorePackagePrefixes = c.canonicalNames(c.ignorePackagePrefixes)
+	c.ignoreFunctions = c.canonicalNames(c.ignoreFunctions)
+	c.allowedModules = c.canonicalNames(c.allowedModules)
+	c.indexIgnoreLists()
+	if c.anchor != "" {
+		c.anchor = c.canonicalName(c.anchor)
+	}
+	c.ignoreReasons = c.canonicalKeys(c.ignoreReasons)
+	c.packageLevels = c.canonicalKeys(c.packageLevels)
+}
+
+// canonicalKeys returns a new map, with the keys run through canonicalName.
+func (c ACaller) canonicalKeys(m map[string]string) map[string]string {
+	if len(m) == 0 {
+		return nil
+	}
+	canonical := make(map[string]string, len(m))
+	for name, value := range m {
+		canonical[c.canonicalName(name)] = value
+	}
+	return canonical
+}
+
+// copyStringMap returns a copy of the map, that is never nil; maps are shared with the copies of the caller, so they
+// are copied before being changed.
+func copyStringMap(m map[string]string) map[string]string {
+	copied := make(map[string]string, len(m)+1)
+	for key, value := range m {
+		copied[key] = value
+	}
+	return copied
+}
+
+// canonicalNames returns a new list, of the names run through canonicalName.
+func (c ACaller) canonicalNames(names []string) []string {
+	if len(names) == 0 {
+		return nil
+	}
+	canonical := make([]string, len(names))
+	for i, name := range names {
+		canonical[i] = c.canonicalName(name)
+	}
+	return canonical
+}
+
+// SetNameCanonicalizer sets the function used to normalize package and function names before they are compared.
+// The function is applied to the entries of the ignore lists as they are added, and to the names of the frames
+// as the stack is walked. Any entries already in the ignore lists are normalized with the new function; so
+// this should be set before entries are added to get consistent results. Setting it to nil turns off normalization
+// for new entries.
+func (c *ACaller) SetNameCanonicalizer(fn func(string) string) {
+	c.canonicalizer = fn
+	if fn == nil {
+		return
+	}
+	c.recanonicalize()
+}
+
+// unifyMethodName rewrites the different forms a method's name can take; the pointer receiver form (pkg.(*T).M),
+// and the method value form (pkg.(*T).M-fm or pkg.T.M-fm), to the value receiver and method expression
+// form (pkg.T.M).
+func unifyMethodName(name string) string {
+	pkg := PackageName(name)
+	if pkg == "" {
+		return name
+	}
+	rest := strings.TrimSuffix(name[len(pkg)+1:], "-fm")
+	if strings.HasPrefix(rest, "(") {
+		if end := strings.Index(rest, ")."); end != -1 {
+			rest = strings.TrimPrefix(rest[1:end], "*") + rest[end+1:]
+		}
+	}
+	return pkg + "." + rest
+}
+
+// SetUnifyMethodForms will set weather the different forms of a method's name are treated as the same name.
+// When true the pointer receiver (pkg.(*T).M), method value (pkg.(*T).M-fm) and method expression (pkg.T.M) forms
+// all match each other; so ignoring one form ignores them all. Any entries already in the ignore lists are
+// unified when this is turned on; turning it off does not restore their original form.
+func (c *ACaller) SetUnifyMethodForms(unify bool) {
+	c.unifyMethodForms = unify
+	if unify {
+		c.recanonicalize()
+	}
+}
+
+// callingPackage returns the package of the first function on the stack that is not in this package.
+func callingPackage() (packageName string) {
 	var (
-		packageName string
-		frames      = getFrames(5, 3)
-		frame       runtime.Frame
-		more        bool
+		frames = getFrames(6, 3)
+		frame  runtime.Frame
+		more   bool
 	)
 	for {
 		frame, more = frames.Next()
@@ -111,7 +431,7 @@ func (c *ACaller) IgnorePackage() {
 		// our package the first piece split on '.'
 		packageName = PackageName(frame.Function)
 		if packageName != "" || !more {
-			if packageName == ourPackageName && more {
+			if isSelfPackage(packageName) && more {
 				// get the next frame only if there are more frames to get
 				continue
 			}
@@ -121,11 +441,257 @@ func (c *ACaller) IgnorePackage() {
 	if packageName == "" {
 		panic("Was not able to get the package name")
 	}
-	if packageName == ourPackageName || packageName == "runtime" {
+	return packageName
+}
+
+// IgnorePackage will mark the calling functions package as a package to ignore when
+// the ACaller function is called in the search for the caller
+//
+// Note this should be called prior to any functions in the package calling the Helper() methods
+// as that functions to it's ignore list who's package is not in the package list. This decreases the amount
+// of memory used by the ACaller structure, as the Caller method always scans the the package ignore list first
+// before scanning the function ignore list.
+func (c *ACaller) IgnorePackage() {
+	packageName := callingPackage()
+	if isSelfPackage(packageName) || packageName == "runtime" {
 		// Skip us or the runtime package
 		return
 	}
+	c.ownIgnoreLists()
+	c.addIgnorePackage(c.canonicalName(packageName))
+}
+
+// addIgnorePackage adds the package name to the package ignore list, unless it is already in the list. It returns
+// weather the package was added. The ignore lists must be owned by c, see ownIgnoreLists.
+func (c *ACaller) addIgnorePackage(packageName string) bool {
+	if c.ignorePackageSet.has(packageName) {
+		return false
+	}
 	c.ignorePackages = append(c.ignorePackages, packageName)
+	c.ignorePackageSet[packageName] = struct{}{}
+	if isPackagePattern(packageName) {
+		c.ignorePackagePatterns = append(c.ignorePackagePatterns, packageName)
+	}
+	return true
+}
+
+// ownIgnoreLists replaces the ignore lists, and their sets, with copies that only c has; so they can be added to
+// in place, without the copies of the caller seeing the change. Adding many entries should be done after a single
+// call, so the lists are only copied once.
+func (c *ACaller) ownIgnoreLists() {
+	c.ignorePackages = append([]string(nil), c.ignorePackages...)
+	c.ignoreFunctions = append([]string(nil), c.ignoreFunctions...)
+	c.ignorePackagePatterns = append([]string(nil), c.ignorePackagePatterns...)
+	c.ignorePackagePrefixes = append([]string(nil), c.ignorePackagePrefixes...)
+	c.ignorePackageSet = copyNameSet(c.ignorePackageSet)
+	c.ignoreFunctionSet = copyNameSet(c.ignoreFunctionSet)
+}
+
+// nameSet is a set of names. A set is shared between the copies of an ACaller, so it is only changed in place by
+// the ACaller that owns it; see ownIgnoreLists.
+type nameSet map[string]struct{}
+
+// copyNameSet returns a copy of the set, that is never nil.
+func copyNameSet(set nameSet) nameSet {
+	copied := make(nameSet, len(set)+1)
+	for name := range set {
+		copied[name] = struct{}{}
+	}
+	return copied
+}
+
+// newNameSet returns the set of the names, or nil if there are none.
+func newNameSet(names []string) nameSet {
+	if len(names) == 0 {
+		return nil
+	}
+	set := make(nameSet, len(names))
+	for _, name := range names {
+		set[name] = struct{}{}
+	}
+	return set
+}
+
+// has returns weather the name is in the set; it is safe to call on a nil set.
+func (set nameSet) has(name string) bool {
+	_, ok := set[name]
+	return ok
+}
+
+// indexIgnoreLists rebuilds the sets, and the patterns, used to look up the entries of the ignore lists. It must be
+// called after the ignore lists are changed.
+func (c *ACaller) indexIgnoreLists() {
+	c.ignorePackageSet = newNameSet(c.ignorePackages)
+	c.ignoreFunctionSet = newNameSet(c.ignoreFunctions)
+	c.ignorePackagePatterns = nil
+	for _, pkgName := range c.ignorePackages {
+		if isPackagePattern(pkgName) {
+			c.ignorePackagePatterns = append(c.ignorePackagePatterns, pkgName)
+		}
+	}
+}
+
+// packageIgnored returns the entry of the package ignore list, or the ignored package prefix, that matches the
+// package, if any.
+func (c *ACaller) packageIgnored(packageName string) (entry string, ok bool) {
+	if c.ignorePackageSet.has(packageName) {
+		return packageName, true
+	}
+	for _, pattern := range c.ignorePackagePatterns {
+		if packageMatches(pattern, packageName) {
+			return pattern, true
+		}
+	}
+	for _, prefix := range c.ignorePackagePrefixes {
+		if strings.HasPrefix(packageName, prefix) {
+			return prefix, true
+		}
+	}
+	return "", false
+}
+
+// IgnoredPackages returns a copy of the package ignore list.
+func (c ACaller) IgnoredPackages() []string { return append([]string(nil), c.ignorePackages...) }
+
+// IgnoredFunctions returns a copy of the function ignore list.
+func (c ACaller) IgnoredFunctions() []string { return append([]string(nil), c.ignoreFunctions...) }
+
+// IgnoreCounts returns the number of packages and functions in the ignore lists.
+func (c ACaller) IgnoreCounts() (packages, functions int) {
+	return len(c.ignorePackages), len(c.ignoreFunctions)
+}
+
+// removeName returns the list without name, and weather name was in the list. The list is copied, as it may be
+// shared with copies of the caller.
+func removeName(list []string, name string) ([]string, bool) {
+	for i := range list {
+		if list[i] == name {
+			return append(append([]string(nil), list[:i]...), list[i+1:]...), true
+		}
+	}
+	return list, false
+}
+
+// UnignorePackage removes the named package (it's import path) from the package ignore list, and the prefix from
+// the list of ignored package prefixes; undoing IgnorePackage, IgnorePackageNamed and IgnorePackagePrefix. It
+// returns weather the package was in either list.
+func (c *ACaller) UnignorePackage(name string) bool {
+	var removedPackage, removedPrefix bool
+	name = c.canonicalName(name)
+	c.ignorePackages, removedPackage = removeName(c.ignorePackages, name)
+	c.ignorePackagePrefixes, removedPrefix = removeName(c.ignorePackagePrefixes, name)
+	c.indexIgnoreLists()
+	return removedPackage || removedPrefix
+}
+
+// UnignoreFunction removes the named function, in the callers package, from the function ignore list, along with
+// the reason it was ignored; undoing IgnoreFunction. The name can also be fully qualified (package.FunctionName),
+// undoing IgnoreFunctionReason, LoadIgnoreFunctions and WithIgnoredFunctions; a name with a package path is always
+// taken as fully qualified, otherwise it is if there is no such function in the callers package. It returns weather
+// the function was in the list.
+func (c *ACaller) UnignoreFunction(name string) (removed bool) {
+	fullName := c.canonicalName(name)
+	if !strings.Contains(name, "/") {
+		if local := c.canonicalName(callingPackage() + "." + name); c.ignoreFunctionSet.has(local) {
+			fullName = local
+		}
+	}
+	c.ignoreFunctions, removed = removeName(c.ignoreFunctions, fullName)
+	c.indexIgnoreLists()
+	if _, ok := c.ignoreReasons[fullName]; ok {
+		// the map may be shared with copies of the caller
+		c.ignoreReasons = copyStringMap(c.ignoreReasons)
+		delete(c.ignoreReasons, fullName)
+	}
+	return removed
+}
+
+// IgnorePackageNamed will add the named package (it's import path) to the package ignore list. The name can be a
+// glob pattern; github.com/myorg/middleware/* ignores every package below github.com/myorg/middleware, and
+// github.com/myorg/*/middleware ignores the middleware package of each of the directories in github.com/myorg.
+// See path.Match for the syntax of the patterns.
+func (c *ACaller) IgnorePackageNamed(name string) {
+	c.ownIgnoreLists()
+	c.addIgnorePackageNamed(name)
+}
+
+// addIgnorePackageNamed does the work of IgnorePackageNamed, for adding many packages after a single call to
+// ownIgnoreLists.
+func (c *ACaller) addIgnorePackageNamed(name string) {
+	if isSelfPackage(name) || name == "runtime" {
+		// Skip us or the runtime package, they are always ignored
+		return
+	}
+	c.addIgnorePackage(c.canonicalName(name))
+}
+
+// IgnorePackagePrefix will skip the frames of any package whose import path starts with prefix; so every package
+// of a framework, and it's sub packages, can be ignored without naming each of them. The prefix is compared as a
+// string, so company.com/telemetry also matches company.com/telemetryx; end it with a / to only match the
+// sub packages.
+func (c *ACaller) IgnorePackagePrefix(prefix string) {
+	if prefix == "" {
+		// this would ignore every frame
+		return
+	}
+	prefix = c.canonicalName(prefix)
+	for _, ignored := range c.ignorePackagePrefixes {
+		if ignored == prefix {
+			return
+		}
+	}
+	// the list may be shared with copies of the caller
+	c.ignorePackagePrefixes = append(append([]string(nil), c.ignorePackagePrefixes...), prefix)
+}
+
+// KnownLoggerPackages are the packages of the popular logging libraries, that are ignored by IgnoreKnownLoggers.
+// Packages can be added to the list, before IgnoreKnownLoggers is called.
+var KnownLoggerPackages = []string{
+	// standard library
+	"log",
+	"log/slog",
+	// logrus
+	"github.com/sirupsen/logrus",
+	// zap
+	"go.uber.org/zap",
+	"go.uber.org/zap/zapcore",
+	// zerolog
+	"github.com/rs/zerolog",
+	"github.com/rs/zerolog/log",
+	// klog
+	"k8s.io/klog",
+	"k8s.io/klog/v2",
+}
+
+// IgnoreKnownLoggers will add the packages of the popular logging libraries, as listed in KnownLoggerPackages,
+// to the package ignore list. This is useful when wrapping one of these libraries.
+func (c *ACaller) IgnoreKnownLoggers() {
+	c.ownIgnoreLists()
+	for _, pkgName := range KnownLoggerPackages {
+		c.addIgnorePackageNamed(pkgName)
+	}
+}
+
+// IgnorePackageWithTests is like IgnorePackage, but will also ignore the test variants of the calling
+// functions package. For a package foo, these are the external test package (foo_test), and the name of the
+// package as instrumented by go test (foo [foo.test]). This can be called from either foo or foo_test.
+func (c *ACaller) IgnorePackageWithTests() {
+	packageName := strings.TrimSuffix(callingPackage(), "_test")
+	if packageName == "runtime" {
+		return
+	}
+	c.ownIgnoreLists()
+	for _, pkgName := range []string{
+		packageName,
+		packageName + "_test",
+		packageName + " [" + packageName + ".test]",
+	} {
+		if isSelfPackage(pkgName) {
+			// Skip us, but not our tests
+			continue
+		}
+		c.addIgnorePackage(c.canonicalName(pkgName))
+	}
 }
 
 // Helper will mark the calling function as a function to ignore when
@@ -154,7 +720,7 @@ func (c *ACaller) Helper() {
 		}
 		packageName = PackageName(frame.Function)
 
-		if packageName != ourPackageName && packageName != "runtime" {
+		if !isSelfPackage(packageName) && packageName != "runtime" {
 			// This check should not be necessary; as we skip the first three frames, which should be the
 			// runtime.Caller
 			// github.com/gdey/caller.getFrames
@@ -167,25 +733,24 @@ func (c *ACaller) Helper() {
 			return // there is no frames, so return the package
 		}
 	}
+	functionName := c.canonicalName(frame.Function)
 	// Let's make sure we don't already have this in our ignore list
-	for _, fnName := range c.ignoreFunctions {
-		if frame.Function == fnName {
-			return // already have it in out list
-		}
+	if c.ignoreFunctionSet.has(functionName) {
+		return // already have it in out list
 	}
 	// Let's make sure the package is not already ignored; if it is;
 	// then we don't need to add this function
-	if packageName == ourPackageName || packageName == "runtime" {
+	if isSelfPackage(packageName) || packageName == "runtime" {
 		// Skip us or the runtime package
 		return
 	}
-	for _, pkgName := range c.ignorePackages {
-		if packageName == pkgName {
-			// skip adding it to our list as the package is already in our list
-			return
-		}
+	packageName = c.canonicalName(packageName)
+	if _, ignored := c.packageIgnored(packageName); ignored {
+		// skip adding it to our list as the package is already in our list
+		return
 	}
-	c.ignoreFunctions = append(c.ignoreFunctions, frame.Function)
+	c.ownIgnoreLists()
+	c.addIgnoreFunction(functionName)
 }
 
 // IgnoreFunction will mark the named function in the callers package as a function to ignore when
@@ -214,7 +779,7 @@ func (c *ACaller) IgnoreFunction(name string) {
 		}
 		packageName = PackageName(frame.Function)
 
-		if packageName != ourPackageName && packageName != "runtime" {
+		if !isSelfPackage(packageName) && packageName != "runtime" {
 			// This check should not be necessary; as we skip the first three frames, which should be the
 			// runtime.Caller
 			// github.com/gdey/caller.getFrames
@@ -227,60 +792,472 @@ func (c *ACaller) IgnoreFunction(name string) {
 			return // there is no frames, so return the package
 		}
 	}
-	fullFunctionName := packageName + "." + name
+	fullFunctionName := c.canonicalName(packageName + "." + name)
 	// Let's make sure we don't already have this in our ignore list
-	for _, fnName := range c.ignoreFunctions {
-		if fullFunctionName == fnName {
-			return // already have it in out list
-		}
+	if c.ignoreFunctionSet.has(fullFunctionName) {
+		return // already have it in out list
 	}
 	// Let's make sure the package is not already ignored; if it is;
 	// then we don't need to add this function
-	if packageName == ourPackageName || packageName == "runtime" {
+	if isSelfPackage(packageName) || packageName == "runtime" {
 		// Skip us or the runtime package
 		return
 	}
-	for _, pkgName := range c.ignorePackages {
-		if packageName == pkgName {
-			// skip adding it to our list as the package is already in our list
-			return
+	packageName = c.canonicalName(packageName)
+	if _, ignored := c.packageIgnored(packageName); ignored {
+		// skip adding it to our list as the package is already in our list
+		return
+	}
+	c.ownIgnoreLists()
+	c.addIgnoreFunction(fullFunctionName)
+}
+
+// IgnoreReceiverMethods will mark the given methods, of the named type in the callers package, as functions to
+// ignore when the ACaller function is called in the search for the caller. Both the value and the pointer receiver
+// forms of the methods are ignored; so IgnoreReceiverMethods("Log", "Info") ignores Log.Info and (*Log).Info.
+func (c *ACaller) IgnoreReceiverMethods(typeName string, methods ...string) {
+	packageName := callingPackage()
+	if isSelfPackage(packageName) || packageName == "runtime" {
+		// Skip us or the runtime package
+		return
+	}
+	typeName = strings.TrimPrefix(strings.TrimSuffix(strings.TrimPrefix(typeName, "("), ")"), "*")
+	c.ownIgnoreLists()
+	for _, method := range methods {
+		c.addIgnoreFunction(c.canonicalName(packageName + "." + typeName + "." + method))
+		c.addIgnoreFunction(c.canonicalName(packageName + ".(*" + typeName + ")." + method))
+	}
+}
+
+// IgnoreFileExtensions will skip the frames in files that end with any of the given extensions; such as .pb.go for
+// generated protobuf files. The leading dot is added if it is missing.
+func (c *ACaller) IgnoreFileExtensions(exts ...string) {
+	// the list may be shared with copies of the caller
+	extensions := append([]string(nil), c.ignoreFileExtensions...)
+next:
+	for _, ext := range exts {
+		if ext == "" {
+			continue
+		}
+		if !strings.HasPrefix(ext, ".") {
+			ext = "." + ext
 		}
+		for _, ignored := range extensions {
+			if ignored == ext {
+				continue next
+			}
+		}
+		extensions = append(extensions, ext)
+	}
+	c.ignoreFileExtensions = extensions
+}
+
+// addIgnoreFunction adds the fully qualified function name to the function ignore list, unless it,
+// or it's package, is already being ignored. It returns weather the function was added. The ignore lists must be
+// owned by c, see ownIgnoreLists.
+func (c *ACaller) addIgnoreFunction(fullName string) bool {
+	if c.ignoreFunctionSet.has(fullName) {
+		return false // already have it in out list
+	}
+	if _, ignored := c.packageIgnored(PackageName(fullName)); ignored {
+		// skip adding it to our list as the package is already in our list
+		return false
+	}
+	c.ignoreFunctions = append(c.ignoreFunctions, fullName)
+	c.ignoreFunctionSet[fullName] = struct{}{}
+	return true
+}
+
+// IgnoreFunctionReason will add the fully qualified function name (package.FunctionName) to the function
+// ignore list, along with the reason it is being ignored. The reason can be retrieved with IgnoreReasons, which
+// helps document large ignore configurations. If the function is already in the list, only the reason is updated.
+func (c *ACaller) IgnoreFunctionReason(fullName, reason string) {
+	fullName = c.canonicalName(fullName)
+	// the map may be shared with copies of the caller
+	c.ignoreReasons = copyStringMap(c.ignoreReasons)
+	c.ignoreReasons[fullName] = reason
+	c.ownIgnoreLists()
+	c.addIgnoreFunction(fullName)
+}
+
+// LoadIgnoreFunctions will add the fully qualified function names (package.FunctionName) read from r to the
+// function ignore list. There should be one name per line; blank lines and anything after a '#' are ignored.
+// The number of functions added to the list is returned.
+func (c *ACaller) LoadIgnoreFunctions(r io.Reader) (count int, err error) {
+	scanner := bufio.NewScanner(r)
+	c.ownIgnoreLists()
+	for scanner.Scan() {
+		line := scanner.Text()
+		if idx := strings.IndexByte(line, '#'); idx != -1 {
+			line = line[:idx]
+		}
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		if c.addIgnoreFunction(c.canonicalName(line)) {
+			count++
+		}
+	}
+	return count, scanner.Err()
+}
+
+// IgnoreReasons returns the reasons, keyed by function name, given for the functions added with
+// IgnoreFunctionReason. The returned map is a copy, and can be modified.
+func (c ACaller) IgnoreReasons() map[string]string {
+	reasons := make(map[string]string, len(c.ignoreReasons))
+	for name, reason := range c.ignoreReasons {
+		reasons[name] = reason
 	}
-	c.ignoreFunctions = append(c.ignoreFunctions, fullFunctionName)
+	return reasons
 }
 
+// SkipRule is the rule that decided weather a frame is skipped.
+type SkipRule string
+
+const (
+	// RuleNone is used when no rule matched the frame; the frame is not skipped.
+	RuleNone SkipRule = ""
+	// RuleRuntime is used for frames in the runtime package, which are always skipped.
+	RuleRuntime SkipRule = "runtime"
+	// RuleSelf is used for frames in this package, which are always skipped.
+	RuleSelf SkipRule = "self"
+	// RuleCompilerWrapper is used for wrapper functions generated by the compiler, see SkipCompilerWrappers.
+	RuleCompilerWrapper SkipRule = "compiler wrapper"
+	// RuleForwarder is used for functions that only forward the call, see IgnoreForwarders.
+	RuleForwarder SkipRule = "forwarder"
+	// RuleNotAllowedModule is used for frames that are not in one of the allowed modules, see SetAllowedModules.
+	RuleNotAllowedModule SkipRule = "not allowed module"
+	// RuleAllowedModule is used for frames in the ignore lists that are kept because they are in one of the allowed
+	// modules, see DenyThenAllow.
+	RuleAllowedModule SkipRule = "allowed module"
+	// RuleIgnoredPackage is used for frames in a package in the package ignore list.
+	RuleIgnoredPackage SkipRule = "ignored package"
+	// RuleIgnoredFunction is used for frames of a function in the function ignore list.
+	RuleIgnoredFunction SkipRule = "ignored function"
+	// RuleInfoFilter is used for frames skipped by one of the info filters, see AddInfoFilter.
+	RuleInfoFilter SkipRule = "info filter"
+	// RuleIgnoredFileExtension is used for frames in a file with an ignored extension, see IgnoreFileExtensions.
+	RuleIgnoredFileExtension SkipRule = "ignored file extension"
+)
+
 // skipFrame will return weather the given frame is in one of the
 // ignore lists
 func (c *ACaller) skipFrame(frame runtime.Frame) bool {
+	skip, _, _ := c.skipRule(frame)
+	return skip
+}
+
+// skipRule will return weather the given frame should be skipped, the rule that decided it, and the entry
+// of the ignore lists or the allowed modules that the frame matched, if any.
+func (c *ACaller) skipRule(frame runtime.Frame) (skip bool, rule SkipRule, entry string) {
+	// We always skip runtime and this package
+	switch classifyFrame(frame) {
+	case classRuntime:
+		if !c.includeRuntime {
+			return true, RuleRuntime, ""
+		}
+	case classSelf:
+		if !c.includeSelf {
+			return true, RuleSelf, ""
+		}
+	}
 	functionName := frame.Function
 	packageName := PackageName(functionName)
-	// We always skip runtime and this package
-	if packageName == "runtime" || packageName == ourPackageName {
+	if c.skipCompilerWrappers && isCompilerWrapper(frame) {
+		return true, RuleCompilerWrapper, ""
+	}
+	if c.ignoreForwarders && isForwarder(frame) {
+		return true, RuleForwarder, ""
+	}
+	for _, ext := range c.ignoreFileExtensions {
+		if strings.HasSuffix(frame.File, ext) {
+			return true, RuleIgnoredFileExtension, ext
+		}
+	}
+	functionName = c.canonicalName(functionName)
+	packageName = c.canonicalName(packageName)
+	if c.filterMode == DenyThenAllow {
+		// the allowed modules override the ignore lists
+		if rule, entry = c.ignored(frame, packageName, functionName); rule == RuleNone {
+			return false, RuleNone, ""
+		}
+		if rule == RuleInfoFilter {
+			// the allowed modules only override the ignore lists
+			return true, rule, entry
+		}
+		if prefix, ok := c.inAllowedModules(packageName); ok {
+			return false, RuleAllowedModule, prefix
+		}
+		return true, rule, entry
+	}
+	// the allowed modules are a hard gate, that the ignore lists refine
+	if len(c.allowedModules) != 0 {
+		if _, ok := c.inAllowedModules(packageName); !ok {
+			return true, RuleNotAllowedModule, ""
+		}
+	}
+	rule, entry = c.ignored(frame, packageName, functionName)
+	return rule != RuleNone, rule, entry
+}
+
+// isCompilerWrapper returns weather the frame looks like one of the wrapper functions generated by the compiler.
+// These are the method value wrappers (pkg.(*T).M-fm), and the wrappers the compiler generates to adapt methods
+// for embedded types and interfaces, which have a file of "<autogenerated>".
+func isCompilerWrapper(frame runtime.Frame) bool {
+	return frame.File == "<autogenerated>" || strings.HasSuffix(frame.Function, "-fm")
+}
+
+// isClosureName returns weather the function name is that of a closure; i.e. ends in .funcN, or .funcN.N for
+// nested closures.
+func isClosureName(name string) bool {
+	isDigits := func(str string) bool {
+		if str == "" {
+			return false
+		}
+		for _, r := range str {
+			if r < '0' || r > '9' {
+				return false
+			}
+		}
 		return true
 	}
-	// go through the packages first
-	for _, pkgName := range c.ignorePackages {
-		if packageName == pkgName {
-			// skip adding it to our list
-			return true
+	for {
+		idx := strings.LastIndex(name, ".")
+		if idx == -1 {
+			return false
+		}
+		last := name[idx+1:]
+		if strings.HasPrefix(last, "func") {
+			return isDigits(last[len("func"):])
 		}
+		if !isDigits(last) {
+			return false
+		}
+		name = name[:idx]
 	}
-	// go through the functions next.
-	for _, fnName := range c.ignoreFunctions {
-		if functionName == fnName {
-			return true
+}
+
+// isForwarder returns weather the frame looks like a function that only forwards the call to another function.
+// Go does not record this, so this is a heuristic; method value thunks (pkg.(*T).M-fm) are forwarders, and so are
+// closures where the call is made on the line the closure starts on; i.e. func() { return fn() }. Inlined closures
+// can not be recognized, as the runtime does not provide the line they start on.
+func isForwarder(frame runtime.Frame) bool {
+	if strings.HasSuffix(frame.Function, "-fm") {
+		return true
+	}
+	if frame.Func == nil || !isClosureName(frame.Function) {
+		return false
+	}
+	_, line := frame.Func.FileLine(frame.Func.Entry())
+	return line == frame.Line
+}
+
+// IgnoreForwarders will skip frames that look like they are only forwarding the call to another function. Go does
+// not record this, so this is a best effort; method value thunks (pkg.(*T).M-fm) and closures that make their call
+// on the line they start on (func() { return fn() }) are recognized. Inlined closures are not recognized.
+func (c *ACaller) IgnoreForwarders() { c.ignoreForwarders = true }
+
+// SkipCompilerWrappers will set weather frames of wrapper functions generated by the compiler should be skipped;
+// so the caller is the user code that called the wrapper.
+func (c *ACaller) SkipCompilerWrappers(skip bool) { c.skipCompilerWrappers = skip }
+
+// ignored returns the rule, and the entry, of the ignore list the package or function matched; or the info filter
+// rule if one of the filters matched the frame. The categories are checked in the check order, see SetCheckOrder.
+func (c *ACaller) ignored(frame runtime.Frame, packageName, functionName string) (rule SkipRule, entry string) {
+	order := c.checkOrder
+	if len(order) == 0 {
+		order = defaultCheckOrder
+	}
+	for _, kind := range order {
+		switch kind {
+		case CheckInfoFilters:
+			if len(c.infoFilters) == 0 {
+				continue
+			}
+			info := frameInfo(frame)
+			for _, filter := range c.infoFilters {
+				if filter(info) {
+					return RuleInfoFilter, ""
+				}
+			}
+		case CheckPackages:
+			if pkgName, ok := c.packageIgnored(packageName); ok {
+				c.usage.hit(pkgName)
+				return RuleIgnoredPackage, pkgName
+			}
+		case CheckFunctions:
+			if c.ignoreFunctionSet.has(functionName) {
+				c.usage.hit(functionName)
+				return RuleIgnoredFunction, functionName
+			}
 		}
 	}
-	return false
+	return RuleNone, ""
+}
+
+// isPackagePattern returns weather the entry of the package ignore list is a glob pattern.
+func isPackagePattern(entry string) bool {
+	// the names of the test variants of a package have brackets, but are not patterns
+	return strings.ContainsAny(entry, "*?[") && !strings.Contains(entry, " [")
+}
+
+// packageMatches returns weather the package matches the entry of the package ignore list. Entries without any
+// glob characters must be the same as the package; as must the entries of the test variants, whose names have
+// brackets. A trailing /* matches every package below the directory, so github.com/myorg/middleware/* ignores the
+// whole directory tree; otherwise the entry is matched with path.Match, where * does not match a /.
+func packageMatches(entry, packageName string) bool {
+	if entry == packageName {
+		return true
+	}
+	if !isPackagePattern(entry) {
+		return false
+	}
+	if strings.HasSuffix(entry, "/*") {
+		dir := entry[:len(entry)-2]
+		// match the directory against the same number of elements of the package, and require there to be more
+		n := strings.Count(dir, "/") + 1
+		elements := strings.SplitN(packageName, "/", n+1)
+		if len(elements) <= n {
+			return false
+		}
+		matched, err := path.Match(dir, strings.Join(elements[:n], "/"))
+		return err == nil && matched
+	}
+	matched, err := path.Match(entry, packageName)
+	return err == nil && matched
+}
+
+// CheckKind is one of the categories of the ignore rules, see SetCheckOrder.
+type CheckKind uint8
+
+const (
+	// CheckInfoFilters checks the filters added with AddInfoFilter.
+	CheckInfoFilters CheckKind = iota
+	// CheckPackages checks the package ignore list.
+	CheckPackages
+	// CheckFunctions checks the function ignore list.
+	CheckFunctions
+)
+
+// defaultCheckOrder is the order the categories of the ignore rules are checked in, if none is set
+var defaultCheckOrder = []CheckKind{CheckInfoFilters, CheckPackages, CheckFunctions}
+
+// SetCheckOrder changes the order the categories of the ignore rules are checked in. The first matching rule
+// skips the frame, so checking the category that matches most frames first can save work; for example, when most
+// of the ignore rules are functions. The order does not change which frames are skipped, only the rule reported
+// for a frame matching more then one rule. Categories left out of the order are checked last, in the default
+// order; so an empty order restores the default.
+func (c *ACaller) SetCheckOrder(order []CheckKind) {
+	var (
+		checkOrder = make([]CheckKind, 0, len(defaultCheckOrder))
+		seen       = make(map[CheckKind]bool, len(defaultCheckOrder))
+	)
+	for _, kind := range append(append([]CheckKind(nil), order...), defaultCheckOrder...) {
+		if seen[kind] || kind > CheckFunctions {
+			continue
+		}
+		seen[kind] = true
+		checkOrder = append(checkOrder, kind)
+	}
+	c.checkOrder = checkOrder
+}
+
+// inAllowedModules returns the allowed prefix the package starts with, if any.
+func (c *ACaller) inAllowedModules(packageName string) (prefix string, ok bool) {
+	for _, allowed := range c.allowedModules {
+		if strings.HasPrefix(packageName, allowed) {
+			return allowed, true
+		}
+	}
+	return "", false
+}
+
+// checkedRules returns the number of the configured rules; the info filters, the allowed modules and the entries of
+// the ignore lists, that are evaluated against a frame that is kept.
+func (c *ACaller) checkedRules(frame runtime.Frame) (count int) {
+	count = len(c.infoFilters) + len(c.ignorePackages) + len(c.ignorePackagePrefixes) + len(c.ignoreFunctions)
+	packageName := c.canonicalName(PackageName(frame.Function))
+	if c.filterMode == DenyThenAllow {
+		// the frame's hit was already recorded when it was kept
+		quiet := *c
+		quiet.usage = nil
+		if rule, _ := quiet.ignored(frame, packageName, c.canonicalName(frame.Function)); rule == RuleNone {
+			return count
+		}
+	}
+	for _, allowed := range c.allowedModules {
+		count++
+		if strings.HasPrefix(packageName, allowed) {
+			break
+		}
+	}
+	return count
+}
+
+// FilterMode is the order the allowed modules and the ignore lists are applied in when deciding to skip a frame.
+type FilterMode uint8
+
+const (
+	// AllowThenDeny makes the allowed modules a hard gate; frames outside of the allowed modules are always skipped,
+	// and the ignore lists then skip frames from what is left. If there are no allowed modules, all modules are
+	// allowed. This is the default.
+	AllowThenDeny FilterMode = iota
+	// DenyThenAllow makes the allowed modules an override of the ignore lists; frames in the ignore lists are
+	// skipped unless they are in one of the allowed modules. Frames not in the ignore lists are never skipped.
+	DenyThenAllow
+)
+
+// SetFilterMode changes the order the allowed modules and the ignore lists are applied in.
+func (c *ACaller) SetFilterMode(mode FilterMode) { c.filterMode = mode }
+
+// SetAllowedModules will limit the frames considered to those who's package starts with one of the given prefixes.
+// Frames outside of the allowed modules are skipped, in addition to the frames in the ignore lists. Calling this
+// with no prefixes will allow all packages again.
+func (c *ACaller) SetAllowedModules(prefixes ...string) {
+	c.allowedModules = nil
+	for _, prefix := range prefixes {
+		c.allowedModules = append(c.allowedModules, c.canonicalName(prefix))
+	}
 }
 
-// SetNumberOfFramesToGet will change the default number of frame to get.
+// SetIncludeSelf if true will stop the frames of this package from being skipped. This is only useful when debugging
+// this package; as the frames of this package will be reported as the caller.
+func (c *ACaller) SetIncludeSelf(include bool) { c.includeSelf = include }
+
+// SetSkipRuntime if false will stop the frames of the runtime package from being skipped; such as runtime.goexit,
+// or the runtime frames of a panic. The frames are skipped by default.
+func (c *ACaller) SetSkipRuntime(skip bool) { c.includeRuntime = !skip }
+
+// SetNumberOfFramesToGet will change the default number of frame to get. The frames are retrieved in chunks,
+// starting with this number, until a caller is found or the stack ends; so this only needs to be changed so deep
+// stacks take fewer chunks (see FramesSufficient). Sizes that are not larger then DefaultNumberOfFramesToGet are
+// ignored.
 func (c *ACaller) SetNumberOfFramesToGet(size uint) {
 	if size > DefaultNumberOfFramesToGet {
 		c.numFramesToGet = int(size)
 	}
 }
 
+// ResetFrames will restore the default number of frames to get, leaving the ignore lists as they are.
+func (c *ACaller) ResetFrames() { c.numFramesToGet = 0 }
+
+// Reset will clear the ignore lists; the packages, package prefixes, functions and file extensions, along with the
+// reasons the functions were ignored and the info filters (see AddInfoFilter). The default number of frames to get
+// is restored, and the counts of the unused ignore entries (see SetWarnOnUnusedIgnores) start over. The other
+// settings, such as the name canonicalizer, are left as they are.
+func (c *ACaller) Reset() {
+	c.ignorePackages = nil
+	c.ignorePackagePrefixes = nil
+	c.ignoreFunctions = nil
+	c.ignoreFileExtensions = nil
+	c.ignoreReasons = nil
+	c.infoFilters = nil
+	c.indexIgnoreLists()
+	c.usage = c.usage.reset(c.now())
+	c.ResetFrames()
+}
+
 // NumberOfFramesToGet returns the number of frames we will we get from the runtime
 func (c ACaller) NumberOfFramesToGet() int {
 	if c.numFramesToGet != 0 {
@@ -289,26 +1266,700 @@ func (c ACaller) NumberOfFramesToGet() int {
 	return DefaultNumberOfFramesToGet
 }
 
+// totalGrowRetries is the number of extra chunks of frames retrieved from the runtime, by all callers
+var totalGrowRetries uint64
+
+// GrowRetries returns the number of times the frames had to be retrieved again, with a larger chunk, because the
+// stack was deeper then the frames already retrieved. Copies of the caller share the count; the count is only kept
+// for callers built with New, and the default caller. If this keeps going up, the number of frames to get (see
+// SetNumberOfFramesToGet) is too low for the workload.
+func (c ACaller) GrowRetries() uint64 {
+	if c.growRetries == nil {
+		return 0
+	}
+	return atomic.LoadUint64(c.growRetries)
+}
+
+// countGrowRetry records that another chunk of frames is being retrieved.
+func (c ACaller) countGrowRetry() {
+	atomic.AddUint64(&totalGrowRetries, 1)
+	if c.growRetries != nil {
+		atomic.AddUint64(c.growRetries, 1)
+	}
+}
+
+// isStackTerminator returns weather the frame is one of the runtime functions that sit at the bottom
+// of a goroutine's stack. These are never a meaningful caller.
+func isStackTerminator(frame runtime.Frame) bool {
+	return frame.Function == "runtime.goexit" || frame.Function == "runtime.main"
+}
+
+// isTestFunctionName returns weather the full function name is that of a test, benchmark, example or fuzz
+// function, as go test looks for them; the prefix followed by nothing, or by a character that is not a lower case
+// letter.
+func isTestFunctionName(fullFuncName string) bool {
+	name := shortFunctionName(fullFuncName)
+	for _, prefix := range []string{"Test", "Benchmark", "Example", "Fuzz"} {
+		if !strings.HasPrefix(name, prefix) {
+			continue
+		}
+		rest := name[len(prefix):]
+		if rest == "" {
+			return true
+		}
+		if strings.ContainsAny(rest, ".()") {
+			// a closure or a method
+			return false
+		}
+		r, _ := utf8.DecodeRuneInString(rest)
+		return !unicode.IsLower(r)
+	}
+	return false
+}
+
+// SetPreferTestFunction if true will have Caller prefer the nearest test function (TestXxx, BenchmarkXxx,
+// ExampleXxx or FuzzXxx) on the stack, not in the ignore lists, as the caller. This makes the logs of a test point at
+// the test, rather then the helpers it calls. If there is no test function on the stack, such as outside of go test
+// or in a sub test, the caller is found as usual.
+func (c *ACaller) SetPreferTestFunction(prefer bool) { c.preferTestFunction = prefer }
+
+// caller does the work for Caller and CallerOK; skip is passed to getFrames.
+func (c ACaller) caller(skip int) (frame runtime.Frame, ok bool) {
+	defer c.usage.call(&c)
+
+	if c.preferTestFunction {
+		// account for our own frame
+		c.walkStack(skip+1, func(f runtime.Frame) bool {
+			if isStackTerminator(f) {
+				return false
+			}
+			if !c.skipFrame(f) && isTestFunctionName(f.Function) {
+				frame, ok = f, true
+				return false
+			}
+			return true
+		})
+		if ok {
+			return frame, true
+		}
+	}
+
+	c.walkStack(skip+1, func(f runtime.Frame) bool {
+		if isStackTerminator(f) {
+			// we reached the bottom of the stack without finding a caller
+			frame = runtime.Frame{}
+			return false
+		}
+		frame = f
+		ok = !c.skipFrame(f)
+		return !ok
+	})
+	// if a caller was not found, frame is the last frame on the stack
+	return frame, ok
+}
+
+// walkStack calls fn, from the innermost to the outermost, with each frame on the stack until fn returns false or
+// the stack ends. The program counters are retrieved in chunks, starting with NumberOfFramesToGet and doubling
+// each time; so a deep stack is walked to the end, without getting all the frames of a shallow stack. skip is
+// the same as for getFrames.
+func (c ACaller) walkStack(skip int, fn func(frame runtime.Frame) bool) {
+	chunk := c.NumberOfFramesToGet()
+	for offset := 0; ; {
+		// capturePCs must always be called from here, so the offset into the stack stays the same
+		pcs, err := capturePCs(chunk, skip+offset)
+		if err != nil {
+			// the stack ended at the end of the last chunk
+			return
+		}
+		if !eachFrame(runtime.CallersFrames(pcs), fn) {
+			return
+		}
+		if len(pcs) < chunk {
+			// there are no more frames
+			return
+		}
+		c.countGrowRetry()
+		offset += len(pcs)
+		chunk *= 2
+	}
+}
+
+// stackPCs returns the program counters of the whole stack. They are retrieved in chunks, the same as walkStack; skip
+// is the same as for getFrames. nil is returned if there are no frames.
+func (c ACaller) stackPCs(skip int) (pcs []uintptr) {
+	chunk := c.NumberOfFramesToGet()
+	for {
+		// capturePCs must always be called from here, so the offset into the stack stays the same
+		chunkPCs, err := capturePCs(chunk, skip+len(pcs))
+		if err != nil {
+			return pcs
+		}
+		pcs = append(pcs, chunkPCs...)
+		if len(chunkPCs) < chunk {
+			return pcs
+		}
+		c.countGrowRetry()
+		chunk *= 2
+	}
+}
+
+// frameIterator is the part of runtime.Frames we use to walk the frames; it allows the walk to be
+// tested with synthetic frames.
+type frameIterator interface {
+	Next() (frame runtime.Frame, more bool)
+}
+
+// eachFrame calls fn with each of the frames until fn returns false; returning false if fn did.
+func eachFrame(frames frameIterator, fn func(frame runtime.Frame) bool) bool {
+	more := true
+	for more {
+		var frame runtime.Frame
+		frame, more = frames.Next()
+		if !fn(frame) {
+			return false
+		}
+	}
+	return true
+}
+
+// frameSearch is the search for the first frame that is not in the ignore lists and is accepted by accept. The
+// frames are given to next in turn, see firstFrame and findFrame.
+type frameSearch struct {
+	c      *ACaller
+	accept func(runtime.Frame) bool
+	frame  runtime.Frame
+	ok     bool
+}
+
+// next returns false once the search is over; the frame was found, or the bottom of the stack was reached.
+func (s *frameSearch) next(frame runtime.Frame) bool {
+	if isStackTerminator(frame) {
+		return false
+	}
+	if !s.c.skipFrame(frame) && s.accept(frame) {
+		s.frame, s.ok = frame, true
+		return false
+	}
+	return true
+}
+
+// firstFrame returns the first frame from frames that is not in the ignore lists and is accepted by accept.
+func (c ACaller) firstFrame(frames frameIterator, accept func(runtime.Frame) bool) (frame runtime.Frame, ok bool) {
+	search := frameSearch{c: &c, accept: accept}
+	eachFrame(frames, search.next)
+	return search.frame, search.ok
+}
+
+// findFrame is like firstFrame, for the frames on the stack; skip is passed to getFrames.
+func (c ACaller) findFrame(skip int, accept func(runtime.Frame) bool) (frame runtime.Frame, ok bool) {
+	search := frameSearch{c: &c, accept: accept}
+	// account for our own frame
+	c.walkStack(skip+1, search.next)
+	return search.frame, search.ok
+}
+
+// callerErr does the work for CallerErr; skip is passed to getFrames.
+func (c ACaller) callerErr(skip int) (frame runtime.Frame, err error) {
+	err = ErrNotEnoughFrames
+	// account for our own frame
+	c.walkStack(skip+1, func(f runtime.Frame) bool {
+		if isStackTerminator(f) {
+			err = ErrNoCallers
+			return false
+		}
+		if !c.skipFrame(f) {
+			frame, err = f, nil
+			return false
+		}
+		return true
+	})
+	if err != nil {
+		return runtime.Frame{}, err
+	}
+	return frame, nil
+}
+
+// CallerErr is like CallerOK, but returns why the caller was not found. ErrNoCallers is returned if the bottom
+// of the stack was reached without finding a caller, and ErrNotEnoughFrames if the frames ran out first.
+func (c ACaller) CallerErr() (frame runtime.Frame, err error) {
+	defer c.usage.call(&c)
+	return c.callerErr(5)
+}
+
+// CapturePCs returns the program counters of the stack, starting with the frame Caller would start with, without
+// removing the frames in the ignore lists. They can be turned into frames with runtime.CallersFrames, for custom
+// processing of the stack. nil is returned if there are no frames.
+func (c ACaller) CapturePCs() []uintptr { return c.stackPCs(5) }
+
 // Caller will walk up the call stack to find the caller that lead to the call of the function
 // that called Caller. It will ignore any caller in the frame that is in it's ignore lists.
+// If the bottom of the stack is reached without finding a caller the zero frame is returned.
 func (c ACaller) Caller() (frame runtime.Frame) {
+	frame, _ = c.caller(5)
+	return frame
+}
+
+// CallerOK is like Caller, but ok will be false if a caller outside of the ignore lists was not found.
+// The runtime functions at the bottom of the stack (runtime.goexit and runtime.main) are never returned.
+func (c ACaller) CallerOK() (frame runtime.Frame, ok bool) { return c.caller(5) }
+
+// CallerFinal is like CallerOK, but isLast will be true if the caller is the last frame available; there are no more
+// frames retrieved after it, other then the bottom of the stack (runtime.goexit or runtime.main). This tells a
+// caller found with more of the stack after it, from a caller that is all there was.
+func (c ACaller) CallerFinal() (frame runtime.Frame, isLast bool, ok bool) {
+	c.walkStack(5, func(f runtime.Frame) bool {
+		if ok {
+			// the frame after the caller
+			isLast = isStackTerminator(f)
+			return false
+		}
+		if isStackTerminator(f) {
+			return false
+		}
+		if !c.skipFrame(f) {
+			// there may be no frames after the caller
+			frame, isLast, ok = f, true, true
+		}
+		return true
+	})
+	return frame, isLast, ok
+}
+
+// CallerLikeStdLog is like runtime.Caller(calldepth), called from the function that is calling CallerLikeStdLog;
+// but will then skip frames in the ignore lists starting from that frame. This matches the calldepth of
+// log.Output, which eases porting code that uses it. A calldepth of 0 is the function calling CallerLikeStdLog.
+func (c ACaller) CallerLikeStdLog(calldepth int) (frame runtime.Frame, ok bool) {
+	if calldepth < 0 {
+		return frame, false
+	}
+	return c.findFrame(4+calldepth, func(runtime.Frame) bool { return true })
+}
+
+// callerAt returns the n-th (starting at 0) frame that is not in the ignore lists; skip is passed to getFrames.
+func (c ACaller) callerAt(skip, n int) (frame runtime.Frame, ok bool) {
+	if n < 0 {
+		return frame, false
+	}
+	// account for our own frame
+	return c.findFrame(skip+1, func(runtime.Frame) bool {
+		if n == 0 {
+			return true
+		}
+		n--
+		return false
+	})
+}
+
+// CallerAt is like CallerOK, but will return the n-th caller not in the ignore lists; CallerAt(0) is the same as
+// CallerOK, CallerAt(1) is the caller of that caller, and so on.
+func (c ACaller) CallerAt(n int) (frame runtime.Frame, ok bool) { return c.callerAt(5, n) }
+
+// CallerOfCaller is like CallerOK, but returns the caller of the caller. This is the common need of assertion
+// helpers; where the assertion wants to report the function that called the helper that called the assertion.
+// It is the same as CallerAt(1).
+func (c ACaller) CallerOfCaller() (frame runtime.Frame, ok bool) { return c.callerAt(5, 1) }
+
+// CallerHint is like Caller, but will return the first of the hints if no caller is found on the current stack.
+//
+// The stack only contains the calls made on the current goroutine; once control passes through a channel, or
+// to a new goroutine, the function that sent the work is not on the stack of the function doing the work. Hints
+// let the sender's frame, captured by the sender with Caller, be passed along and used by the receiver when nothing
+// on the receiver's stack is outside of the ignore lists.
+func (c ACaller) CallerHint(hints ...runtime.Frame) runtime.Frame {
+	frame, ok := c.caller(5)
+	if ok || len(hints) == 0 {
+		return frame
+	}
+	return hints[0]
+}
+
+// CallerInSamePackageAs returns weather the caller is in the same package as the given fully qualified function
+// name. This allows a function to check that it is only being called from within a given package.
+func (c ACaller) CallerInSamePackageAs(fullFuncName string) bool {
+	frame, ok := c.caller(5)
+	if !ok {
+		return false
+	}
+	return c.canonicalName(PackageName(frame.Function)) == c.canonicalName(PackageName(fullFuncName))
+}
+
+// CallerFingerprint returns a short string identifying the call site of the caller, of the form
+// package.Function:line. The fingerprint is the same for every call from the same site, and across runs of
+// the same binary; which makes it useful for deduplicating log lines. The unknown name (see SetUnknownName) is
+// returned if the caller could not be found.
+func (c ACaller) CallerFingerprint() string {
+	frame, ok := c.caller(5)
+	if !ok {
+		return c.unknown()
+	}
+	return frame.Function + ":" + strconv.Itoa(frame.Line)
+}
+
+// CallerLocation is like CallerOK, but returns the raw pieces a profiler, such as pprof, uses for a location:
+// the full function name, the file, and the line.
+func (c ACaller) CallerLocation() (function, file string, line int, ok bool) {
+	frame, ok := c.caller(5)
+	return frame.Function, frame.File, frame.Line, ok
+}
+
+// CallerLineNumber is like CallerOK, but only returns the line number of the caller. This is for loggers
+// that already know the file they are logging from.
+func (c ACaller) CallerLineNumber() (line int, ok bool) {
+	frame, ok := c.caller(5)
+	return frame.Line, ok
+}
+
+// CallerFunctionLine is like CallerLocation, but returns where the caller's function is declared, rather then
+// the line that made the call. ok is false if there is no caller, or the declaration could not be found; which is
+// the case for an inlined caller, as the runtime only knows where the function it was inlined into is declared.
+func (c ACaller) CallerFunctionLine() (file string, declLine int, ok bool) {
+	frame, ok := c.caller(5)
+	if !ok || frame.Entry == 0 || frame.Func == nil {
+		// inlined functions do not have a Func; the Entry is of the function they were inlined into
+		return "", 0, false
+	}
+	fn := runtime.FuncForPC(frame.Entry)
+	if fn == nil {
+		return "", 0, false
+	}
+	file, declLine = fn.FileLine(frame.Entry)
+	return file, declLine, declLine != 0
+}
+
+// CallerWithSource is like CallerOK, but will skip frames that do not have a source file. This is useful
+// for stripped or partial builds where the nearest caller may not be resolvable to a file.
+func (c ACaller) CallerWithSource() (frame runtime.Frame, ok bool) {
+	return c.findFrame(5, hasSource)
+}
+
+// hasSource returns weather the frame has a source file
+func hasSource(frame runtime.Frame) bool { return frame.File != "" }
+
+// CallerWithLine is like CallerOK, but will skip frames that do not have a line number, such as those
+// stopped in a function prologue.
+func (c ACaller) CallerWithLine() (frame runtime.Frame, ok bool) {
+	return c.findFrame(5, hasLine)
+}
+
+// hasLine returns weather the frame has a line number
+func hasLine(frame runtime.Frame) bool { return frame.Line > 0 }
+
+// CallerConcrete is like CallerOK, but will skip the wrapper functions the compiler generates to dispatch
+// interface method calls, and method values; so the concrete implementing method is reported.
+// Newer versions of the runtime elide most of these wrappers from the stack already.
+func (c ACaller) CallerConcrete() (frame runtime.Frame, ok bool) {
+	return c.findFrame(5, notCompilerWrapper)
+}
+
+// notCompilerWrapper returns weather the frame is not a compiler generated wrapper
+func notCompilerWrapper(frame runtime.Frame) bool { return !isCompilerWrapper(frame) }
+
+// CallerExcept is like CallerOK, but will also skip the given fully qualified function names, for this call only.
+func (c ACaller) CallerExcept(extra ...string) (frame runtime.Frame, ok bool) {
+	return c.findFrame(5, func(frame runtime.Frame) bool {
+		functionName := c.canonicalName(frame.Function)
+		for _, fnName := range extra {
+			if functionName == c.canonicalName(fnName) {
+				return false
+			}
+		}
+		return true
+	})
+}
+
+// SetAnchor sets the fully qualified function name (package.FunctionName) of the anchor function used by
+// CallerFromAnchor. This is usually the dispatch function of a framework.
+func (c *ACaller) SetAnchor(fullFuncName string) { c.anchor = c.canonicalName(fullFuncName) }
+
+// CallerFromAnchor is like CallerOK, but will return the first frame, not in the ignore lists, above the
+// nearest call of the anchor function (see SetAnchor). The anchor function does not need to be outside the ignore
+// lists. ok is false if there is no anchor set, or the anchor function is not on the stack.
+func (c ACaller) CallerFromAnchor() (frame runtime.Frame, ok bool) {
+	if c.anchor == "" {
+		return frame, false
+	}
+	var (
+		search   = frameSearch{c: &c, accept: func(runtime.Frame) bool { return true }}
+		anchored bool
+	)
+	c.walkStack(5, func(frame runtime.Frame) bool {
+		if !anchored {
+			anchored = c.canonicalName(frame.Function) == c.anchor
+			return true
+		}
+		return search.next(frame)
+	})
+	return search.frame, search.ok
+}
+
+// CallerMatchingRegexp is like CallerOK, but returns the first frame, not in the ignore lists, who's full function
+// name matches re.
+func (c ACaller) CallerMatchingRegexp(re *regexp.Regexp) (frame runtime.Frame, ok bool) {
+	return c.findFrame(5, func(frame runtime.Frame) bool {
+		return re.MatchString(frame.Function)
+	})
+}
+
+// CallerAtFileLine is like CallerOK, but returns the first frame, not in the ignore lists, at the given file and line.
+// file can be the full path of the file, or the end of the path, such as pkg/file.go. ok is false if there is no
+// such frame on the stack.
+func (c ACaller) CallerAtFileLine(file string, line int) (frame runtime.Frame, ok bool) {
+	return c.findFrame(5, func(frame runtime.Frame) bool {
+		if frame.Line != line {
+			return false
+		}
+		return frame.File == file || strings.HasSuffix(frame.File, "/"+strings.TrimPrefix(file, "/"))
+	})
+}
+
+// CallerAboveMatch is like CallerMatchingRegexp, but returns the frame, not in the ignore lists, levels frames above
+// (further from the top of the stack then) the innermost frame who's full function name matches re. The matching
+// frame does not need to be outside the ignore lists; a levels of 0 returns the matching frame itself. ok is false
+// if no frame matches, or there are not enough frames above the match.
+func (c ACaller) CallerAboveMatch(re *regexp.Regexp, levels int) (frame runtime.Frame, ok bool) {
+	if levels < 0 {
+		return frame, false
+	}
+	var (
+		seen   int
+		search = frameSearch{c: &c, accept: func(runtime.Frame) bool {
+			seen++
+			return seen == levels
+		}}
+		matched bool
+	)
+	c.walkStack(5, func(frame runtime.Frame) bool {
+		if matched {
+			return search.next(frame)
+		}
+		if isStackTerminator(frame) {
+			return false
+		}
+		if matched = re.MatchString(frame.Function); matched && levels == 0 {
+			search.frame, search.ok = frame, true
+			return false
+		}
+		return true
+	})
+	return search.frame, search.ok
+}
+
+// CallerAcrossPackageBoundary is like CallerOK, but returns the first frame, not in the ignore lists, that is in a
+// different package than the function calling CallerAcrossPackageBoundary. That is, the caller from outside of
+// the package.
+func (c ACaller) CallerAcrossPackageBoundary() (frame runtime.Frame, ok bool) {
+	var (
+		calleePackage string
+		search        = frameSearch{c: &c, accept: func(frame runtime.Frame) bool {
+			return PackageName(frame.Function) != calleePackage
+		}}
+		first = true
+	)
+	// start with the function calling us, the callee
+	c.walkStack(4, func(frame runtime.Frame) bool {
+		if first {
+			first = false
+			calleePackage = PackageName(frame.Function)
+			return true
+		}
+		return search.next(frame)
+	})
+	return search.frame, search.ok
+}
+
+// isStandardLibrary returns weather the package is part of the standard library. Standard library packages do not
+// have a dot in the first element of their import path; main is excluded, as it is always user code.
+func isStandardLibrary(packageName string) bool {
+	if packageName == "" || packageName == "main" {
+		return false
+	}
+	first := packageName
+	if i := strings.Index(first, "/"); i != -1 {
+		first = first[:i]
+	}
+	return !strings.Contains(first, ".")
+}
+
+// CallerUserCode is like CallerOK, but will also skip frames in the standard library; returning the first frame of
+// user code, not in the ignore lists.
+func (c ACaller) CallerUserCode() (frame runtime.Frame, ok bool) {
+	return c.findFrame(5, func(frame runtime.Frame) bool {
+		return classifyFrame(frame) != classStdlib
+	})
+}
+
+// CallerWithRuleStats is like CallerOK, but also returns the number of the configured rules; the info filters,
+// the allowed modules and the entries of the ignore lists, that were evaluated against the frame before it was kept.
+// A large count means the ignore lists are doing a lot of work for every frame.
+func (c ACaller) CallerWithRuleStats() (frame runtime.Frame, checkedRules int, ok bool) {
+	frame, ok = c.caller(5)
+	if !ok {
+		return frame, 0, false
+	}
+	return frame, c.checkedRules(frame), true
+}
+
+// CallerApproximate is like Caller, but exact will be false if the caller may not be correct. This is the case if the
+// search went through an inlined function, which could hide frames from the search; or the stack ended without
+// reaching the bottom of the stack (see ErrNotEnoughFrames).
+func (c ACaller) CallerApproximate() (frame runtime.Frame, exact bool) {
+	var done bool
+	exact = true
+	c.walkStack(5, func(f runtime.Frame) bool {
+		if isStackTerminator(f) {
+			frame, done = runtime.Frame{}, true
+			return false
+		}
+		if f.Func == nil {
+			// inlined functions do not have a Func
+			exact = false
+		}
+		frame = f
+		done = !c.skipFrame(f)
+		return !done
+	})
+	if !done {
+		// we ran out of frames before the bottom of the stack
+		return frame, false
+	}
+	return frame, exact
+}
+
+// reflectDispatchFunctions are the functions of the reflect package that dispatch a call, or a method, made
+// through reflection.
+var reflectDispatchFunctions = map[string]bool{
+	"reflect.Value.Call":      true,
+	"reflect.Value.CallSlice": true,
+	"reflect.Value.call":      true,
+	"reflect.callReflect":     true,
+	"reflect.callMethod":      true,
+	"reflect.makeFuncStub":    true,
+	"reflect.methodValueCall": true,
+}
+
+// isReflectDispatch returns weather the frame is one of the frames the reflect package adds to dispatch a call.
+func isReflectDispatch(frame runtime.Frame) bool { return reflectDispatchFunctions[frame.Function] }
+
+// CallerPastReflect is like CallerOK, but will skip the frames the reflect package adds when a function is called
+// with reflect.Value.Call. So if the function called through reflection is in the ignore lists, the function that
+// made the call (the invoker) is returned, instead of the reflect package.
+func (c ACaller) CallerPastReflect() (frame runtime.Frame, ok bool) {
+	return c.findFrame(5, func(frame runtime.Frame) bool {
+		return !isReflectDispatch(frame)
+	})
+}
+
+// FirstAfterStdlib is like CallerOK, but returns the first frame, not in the ignore lists, of user code that was
+// called directly by the standard library; such as an http.Handler called by net/http. This is where the user's code
+// was entered from the framework. ok is false if there is no such frame.
+func (c ACaller) FirstAfterStdlib() (frame runtime.Frame, ok bool) {
+	var (
+		candidate runtime.Frame
+		found     bool
+	)
+	c.walkStack(5, func(f runtime.Frame) bool {
+		if isStackTerminator(f) {
+			return false
+		}
+		class := classifyFrame(f)
+		if found && class == classStdlib {
+			frame, ok = candidate, true
+			return false
+		}
+		found = class == classUser && !c.skipFrame(f)
+		candidate = f
+		return true
+	})
+	return frame, ok
+}
+
+// isCgoFrame returns weather the frame is part of a cgo call; either the runtime's cgo call frames, or the
+// functions cgo generates.
+func isCgoFrame(frame runtime.Frame) bool {
+	switch frame.Function {
+	case "runtime.cgocall", "runtime.cgocallback", "runtime.cgocallbackg", "runtime.asmcgocall":
+		return true
+	}
+	name := shortFunctionName(frame.Function)
+	return strings.HasPrefix(name, "_Cfunc_") || strings.HasPrefix(name, "_cgo_") ||
+		strings.HasPrefix(name, "_cgoexp_")
+}
+
+// cgoSearch is the search for a frame, up to the bottom of the stack, that is part of a cgo call. The frames are
+// given to next in turn.
+type cgoSearch struct {
+	crosses bool
+}
+
+// next returns false once the search is over; a cgo frame was found, or the bottom of the stack was reached.
+func (s *cgoSearch) next(frame runtime.Frame) bool {
+	s.crosses = isCgoFrame(frame)
+	return !s.crosses && !isStackTerminator(frame)
+}
+
+// crossesCgo returns weather any of the frames, up to the bottom of the stack, is part of a cgo call.
+func crossesCgo(frames frameIterator) bool {
+	var search cgoSearch
+	eachFrame(frames, search.next)
+	return search.crosses
+}
+
+// CallerCrossesCgo returns weather the stack, from the function calling CallerCrossesCgo down, goes through a cgo
+// call; for example, Go code called back from C. If it does the caller may be on the other side of the cgo call.
+func (c ACaller) CallerCrossesCgo() bool {
+	var search cgoSearch
+	c.walkStack(5, search.next)
+	return search.crosses
+}
+
+// FramesSufficient reports weather the number of frames to get (see SetNumberOfFramesToGet) is enough to find the
+// caller, from the function calling FramesSufficient, in the first chunk of frames; and the number of frames that
+// were needed, which is the number of frames to the bottom of the stack if there is no caller. The caller is found
+// either way, but each extra chunk is another, and larger, walk of the stack by the runtime; so this can be used as
+// a check at start up.
+func (c ACaller) FramesSufficient() (needed int, ok bool) {
+	c.walkStack(5, func(frame runtime.Frame) bool {
+		if isStackTerminator(frame) {
+			return false
+		}
+		needed++
+		return c.skipFrame(frame)
+	})
+	return needed, needed <= c.NumberOfFramesToGet()
+}
+
+// CallerWithinBudget is like Caller, but will look at no more then maxFrames frames in it's search for the caller.
+// This puts a hard ceiling on the cost of the call, independent of the depth of the stack. If no frame outside
+// of the ignore lists is found within the budget, ok will be false.
+func (c ACaller) CallerWithinBudget(maxFrames int) (frame runtime.Frame, ok bool) {
+	if maxFrames <= 0 {
+		return frame, false
+	}
 	var more bool
 
-	frames := getFrames(c.NumberOfFramesToGet(), 4)
-	for {
+	frames := getFrames(maxFrames, 4)
+	for i := 0; i < maxFrames; i++ {
 		frame, more = frames.Next()
-		if !c.skipFrame(frame) || !more {
-			// we will return the last frame. (It is possible that out size is not big enough)
+		if isStackTerminator(frame) {
+			break
+		}
+		if !c.skipFrame(frame) {
+			return frame, true
+		}
+		if !more {
 			break
 		}
 	}
-	return frame
+	return runtime.Frame{}, false
 }
 
 // Caller will walk up the call stack to find the caller that lead to the call of this function. It will ignore any callers
 // in the frame that is in the ignore lists.
 func Caller() (frame runtime.Frame) { return defaultCaller.Caller() }
 
+// CallerOK is like Caller, but ok will be false if a caller outside of the ignore lists was not found.
+func CallerOK() (frame runtime.Frame, ok bool) { return defaultCaller.CallerOK() }
+
 // Helper will add the calling function to the function ignore list
 func Helper() { defaultCaller.Helper() }
 
@@ -318,6 +1969,12 @@ func IgnoreFunction(name string) { defaultCaller.IgnoreFunction(name) }
 // IgnorePackage will add the package of the calling function to the packages ignore list
 func IgnorePackage() { defaultCaller.IgnorePackage() }
 
+// UnignorePackage will remove the named package from the packages ignore list, see ACaller.UnignorePackage
+func UnignorePackage(name string) bool { return defaultCaller.UnignorePackage(name) }
+
+// UnignoreFunction will remove the named function, in the callers package, from the functions ignore list
+func UnignoreFunction(name string) bool { return defaultCaller.UnignoreFunction(name) }
+
 // SetNumberOfFramesToGet will change the default number of frames to retrieve. This should not changed unless you know
 // the it needs to be changed
 func SetNumberOfFramesToGet(size uint) { defaultCaller.SetNumberOfFramesToGet(size) }
@@ -325,6 +1982,21 @@ func SetNumberOfFramesToGet(size uint) { defaultCaller.SetNumberOfFramesToGet(si
 // NumberOfFramesToGet will return the current configured number of frames to get
 func NumberOfFramesToGet() int { return defaultCaller.NumberOfFramesToGet() }
 
+// GrowRetries returns the number of times the frames had to be retrieved again, with a larger chunk, by all the
+// callers; see ACaller.GrowRetries.
+func GrowRetries() uint64 { return atomic.LoadUint64(&totalGrowRetries) }
+
+// IgnoreCounts returns the number of packages and functions in the ignore lists.
+func IgnoreCounts() (packages, functions int) { return defaultCaller.IgnoreCounts() }
+
+// ResetFrames will restore the default number of frames to get, leaving the ignore lists as they are.
+func ResetFrames() { defaultCaller.ResetFrames() }
+
+// Reset will clear the ignore lists, and filters, and restore the default number of frames to get, of the default
+// caller; see ACaller.Reset. This returns the ignore lists of the default caller to a clean slate; such as between
+// tests.
+func Reset() { defaultCaller.Reset() }
+
 // Copyright 2021 Gautam Dey. All rights reserved.
 // Use of this source code is governed by the MIT
 // license that can be found in the LICENSE FILE
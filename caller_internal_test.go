@@ -0,0 +1,444 @@
+package caller
+
+import (
+	"reflect"
+	"runtime"
+	"strconv"
+	"testing"
+	"time"
+)
+
+// sliceFrames is a frameIterator over a fixed set of frames
+type sliceFrames []runtime.Frame
+
+func (f *sliceFrames) Next() (frame runtime.Frame, more bool) {
+	if len(*f) == 0 {
+		return frame, false
+	}
+	frame, *f = (*f)[0], (*f)[1:]
+	return frame, len(*f) > 0
+}
+
+func newSliceFrames(frames ...runtime.Frame) *sliceFrames {
+	f := sliceFrames(frames)
+	return &f
+}
+
+func TestACaller_firstFrame_hasSource(t *testing.T) {
+	var c ACaller
+	frames := newSliceFrames(
+		runtime.Frame{Function: ourPackageName + ".Caller", File: "caller.go", Line: 10},
+		runtime.Frame{Function: "example.com/stripped.Func", Line: 20},
+		runtime.Frame{Function: "example.com/app.Func", File: "app.go", Line: 30},
+		runtime.Frame{Function: "runtime.goexit", File: "asm.s", Line: 40},
+	)
+	frame, ok := c.firstFrame(frames, hasSource)
+	if !ok {
+		t.Fatalf("ok, expected true got false")
+	}
+	if frame.Function != "example.com/app.Func" {
+		t.Errorf("frame expected 'example.com/app.Func' got '%v'", frame.Function)
+	}
+
+	frames = newSliceFrames(
+		runtime.Frame{Function: "example.com/stripped.Func", Line: 20},
+		runtime.Frame{Function: "runtime.goexit", File: "asm.s", Line: 40},
+	)
+	if frame, ok = c.firstFrame(frames, hasSource); ok {
+		t.Errorf("ok, expected false got true; frame %v", frame.Function)
+	}
+}
+
+func TestACaller_SkipCompilerWrappers(t *testing.T) {
+	frames := []runtime.Frame{
+		{Function: "example.com/app.(*Log).Info-fm", File: "log.go", Line: 10},
+		{Function: "example.com/app.Log.Info", File: "<autogenerated>", Line: 1},
+	}
+	user := runtime.Frame{Function: "example.com/app.(*Log).Info", File: "log.go", Line: 12}
+
+	var c ACaller
+	for _, frame := range frames {
+		if c.skipFrame(frame) {
+			t.Errorf("skip %v, expected false got true", frame.Function)
+		}
+	}
+	c.SkipCompilerWrappers(true)
+	for _, frame := range frames {
+		if !c.skipFrame(frame) {
+			t.Errorf("skip %v, expected true got false", frame.Function)
+		}
+	}
+	if c.skipFrame(user) {
+		t.Errorf("skip %v, expected false got true", user.Function)
+	}
+	frame, ok := c.firstFrame(newSliceFrames(append(frames, user)...), func(runtime.Frame) bool { return true })
+	if !ok || frame.Function != user.Function {
+		t.Errorf("frame expected '%v' got '%v'", user.Function, frame.Function)
+	}
+}
+
+func TestACaller_firstFrame_notCompilerWrapper(t *testing.T) {
+	var c ACaller
+	frames := newSliceFrames(
+		runtime.Frame{Function: "example.com/app.(*Log).Info", File: "<autogenerated>", Line: 1},
+		runtime.Frame{Function: "example.com/app.Log.Info", File: "log.go", Line: 20},
+		runtime.Frame{Function: "example.com/app.main", File: "main.go", Line: 30},
+	)
+	frame, ok := c.firstFrame(frames, notCompilerWrapper)
+	if !ok || frame.Function != "example.com/app.Log.Info" {
+		t.Errorf("frame expected 'example.com/app.Log.Info' got '%v'", frame.Function)
+	}
+}
+
+func TestIsClosureName(t *testing.T) {
+	tests := map[string]bool{
+		"example.com/app.main.func1":      true,
+		"example.com/app.main.func12.2":   true,
+		"example.com/app.(*T).M.func1":    true,
+		"example.com/app.main":            false,
+		"example.com/app.function":        false,
+		"example.com/app.main.funcs":      false,
+		"example.com/app.(*T).M-fm":       false,
+		"example.com/app.main.func1.2.3x": false,
+	}
+	for name, expected := range tests {
+		if got := isClosureName(name); got != expected {
+			t.Errorf("closure name %v, expected %v got %v", name, expected, got)
+		}
+	}
+}
+
+func TestACaller_IgnoreForwarders(t *testing.T) {
+	var c ACaller
+	thunk := runtime.Frame{Function: "example.com/app.(*Log).Info-fm", File: "log.go", Line: 10}
+	user := runtime.Frame{Function: "example.com/app.main", File: "main.go", Line: 12}
+	if c.skipFrame(thunk) {
+		t.Errorf("skip %v, expected false got true", thunk.Function)
+	}
+	c.IgnoreForwarders()
+	if !c.skipFrame(thunk) {
+		t.Errorf("skip %v, expected true got false", thunk.Function)
+	}
+	if c.skipFrame(user) {
+		t.Errorf("skip %v, expected false got true", user.Function)
+	}
+}
+
+func TestACaller_firstFrame_hasLine(t *testing.T) {
+	var c ACaller
+	frames := newSliceFrames(
+		runtime.Frame{Function: "example.com/app.prologue", File: "app.go"},
+		runtime.Frame{Function: "example.com/app.Func", File: "app.go", Line: 30},
+	)
+	frame, ok := c.firstFrame(frames, hasLine)
+	if !ok || frame.Function != "example.com/app.Func" {
+		t.Errorf("frame expected 'example.com/app.Func' got '%v'", frame.Function)
+	}
+}
+
+// fakeClock is a clock that only moves when told to
+type fakeClock struct {
+	t time.Time
+}
+
+func (f *fakeClock) now() time.Time          { return f.t }
+func (f *fakeClock) advance(d time.Duration) { f.t = f.t.Add(d) }
+
+func TestACaller_setClockForTest(t *testing.T) {
+	var (
+		c     ACaller
+		clock = fakeClock{t: time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC)}
+	)
+	before := time.Now()
+	if now := c.now(); now.Before(before) {
+		t.Errorf("now, expected wall time after %v got %v", before, now)
+	}
+	c.setClockForTest(clock.now)
+	if now := c.now(); !now.Equal(clock.t) {
+		t.Errorf("now, expected %v got %v", clock.t, now)
+	}
+	clock.advance(time.Minute)
+	if now := c.now(); !now.Equal(time.Date(2021, 1, 1, 0, 1, 0, 0, time.UTC)) {
+		t.Errorf("now, expected the clock to advance a minute got %v", now)
+	}
+	// copies of the caller share the clock
+	if now := c.clone().now(); !now.Equal(clock.t) {
+		t.Errorf("now of copy, expected %v got %v", clock.t, now)
+	}
+	c.setClockForTest(nil)
+	if now := c.now(); now.Before(before) {
+		t.Errorf("now, expected wall time after %v got %v", before, now)
+	}
+}
+
+func TestDetectInternalSkipBase(t *testing.T) {
+	const expected = 1 // runtime.Callers reports it's own frame
+	if base := InternalSkipBase(); base != expected {
+		t.Errorf("skip base, expected %v got %v", expected, base)
+	}
+	// the depth of the stack should not change the detection
+	deeper := func() int32 { return func() int32 { return detectInternalSkipBase() }() }
+	if base := deeper(); base != expected {
+		t.Errorf("skip base from deeper, expected %v got %v", expected, base)
+	}
+}
+
+// frameAt returns the frame skip frames up from getFrames
+func frameAt(skip int) runtime.Frame {
+	frame, _ := getFrames(1, skip).Next()
+	return frame
+}
+
+func TestSetInternalSkipBase(t *testing.T) {
+	const expectedName = "github.com/gdey/caller.TestSetInternalSkipBase"
+	defer SetInternalSkipBase(-1)
+
+	// runtime.Callers, getFrames, frameAt, then us
+	if frame := frameAt(3); frame.Function != expectedName {
+		t.Errorf("frame expected '%v' got '%v'", expectedName, frame.Function)
+	}
+	// claiming runtime.Callers reports an extra frame, adds one to every skip
+	SetInternalSkipBase(2)
+	if frame := frameAt(2); frame.Function != expectedName {
+		t.Errorf("frame with a base of 2, expected '%v' got '%v'", expectedName, frame.Function)
+	}
+	SetInternalSkipBase(-1)
+	if base := InternalSkipBase(); base != 1 {
+		t.Errorf("skip base after detecting again, expected 1 got %v", base)
+	}
+	if frame := frameAt(3); frame.Function != expectedName {
+		t.Errorf("frame expected '%v' got '%v'", expectedName, frame.Function)
+	}
+}
+
+func TestIsStandardLibrary(t *testing.T) {
+	tests := map[string]bool{
+		"":                       false,
+		"main":                   false,
+		"fmt":                    true,
+		"net/http":               true,
+		"internal/reflectlite":   true,
+		"github.com/gdey/caller": false,
+		"example.com":            false,
+	}
+	for pkg, expected := range tests {
+		if got := isStandardLibrary(pkg); got != expected {
+			t.Errorf("isStandardLibrary(%q), expected %v got %v", pkg, expected, got)
+		}
+	}
+}
+
+func TestCrossesCgo(t *testing.T) {
+	tests := map[string]struct {
+		frames   []runtime.Frame
+		expected bool
+	}{
+		"go only": {
+			frames: []runtime.Frame{
+				{Function: "example.com/app.Log"},
+				{Function: "example.com/app.main"},
+				{Function: "runtime.main"},
+			},
+		},
+		"cgo call": {
+			frames: []runtime.Frame{
+				{Function: "example.com/app.Log"},
+				{Function: "example.com/app._Cfunc_puts"},
+				{Function: "runtime.cgocall"},
+				{Function: "example.com/app.main"},
+			},
+			expected: true,
+		},
+		"callback from c": {
+			frames: []runtime.Frame{
+				{Function: "example.com/app.callback"},
+				{Function: "example.com/app._cgoexp_1234_callback"},
+				{Function: "runtime.cgocallbackg"},
+			},
+			expected: true,
+		},
+	}
+	for name, tc := range tests {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			if got := crossesCgo(newSliceFrames(tc.frames...)); got != tc.expected {
+				t.Errorf("crosses cgo, expected %v got %v", tc.expected, got)
+			}
+		})
+	}
+}
+
+func TestACaller_CallerCrossesCgo(t *testing.T) {
+	var c ACaller
+	if c.CallerCrossesCgo() {
+		t.Errorf("crosses cgo, expected false got true")
+	}
+}
+
+func TestIsTestFunctionName(t *testing.T) {
+	tests := map[string]bool{
+		"example.com/app.TestCaller":            true,
+		"example.com/app.Test_caller":           true,
+		"example.com/app.Test":                  true,
+		"example.com/app.BenchmarkCaller":       true,
+		"example.com/app.ExampleACaller_Caller": true,
+		"example.com/app.FuzzParse":             true,
+		"example.com/app.Testing":               false,
+		"example.com/app.TestCaller.func1":      false,
+		"example.com/app.(*Tester).TestCaller":  false,
+		"example.com/app.Helper":                false,
+		"example.com/app.Benchmarks":            false,
+	}
+	for name, expected := range tests {
+		if got := isTestFunctionName(name); got != expected {
+			t.Errorf("isTestFunctionName(%q), expected %v got %v", name, expected, got)
+		}
+	}
+}
+
+func TestResolveOurPackage(t *testing.T) {
+	if !SelfPackageResolvedFromFrames() {
+		t.Errorf("resolved from frames, expected true got false")
+	}
+	tests := map[string]struct {
+		walked     string
+		expected   string
+		fromFrames bool
+	}{
+		"frames":  {walked: "example.com/fork/caller", expected: "example.com/fork/caller", fromFrames: true},
+		"empty":   {expected: fallbackPackageName},
+		"runtime": {walked: "runtime", expected: fallbackPackageName},
+	}
+	for name, tc := range tests {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			packageName, fromFrames := resolveOurPackage(func() string { return tc.walked })
+			if packageName != tc.expected {
+				t.Errorf("package expected '%v' got '%v'", tc.expected, packageName)
+			}
+			if fromFrames != tc.fromFrames {
+				t.Errorf("from frames expected %v got %v", tc.fromFrames, fromFrames)
+			}
+		})
+	}
+}
+
+func TestPackageMatches(t *testing.T) {
+	type match struct {
+		entry, packageName string
+	}
+	tests := map[match]bool{
+		{"github.com/myorg/middleware", "github.com/myorg/middleware"}:                       true,
+		{"github.com/myorg/middleware", "github.com/myorg/middleware/auth"}:                  false,
+		{"github.com/myorg/middleware/*", "github.com/myorg/middleware/auth"}:                true,
+		{"github.com/myorg/middleware/*", "github.com/myorg/middleware/auth/jwt"}:            true,
+		{"github.com/myorg/middleware/*", "github.com/myorg/middleware"}:                     false,
+		{"github.com/myorg/middleware/*", "github.com/myorg/middlewares/auth"}:               false,
+		{"github.com/myorg/*/middleware", "github.com/myorg/api/middleware"}:                 true,
+		{"github.com/myorg/*/middleware", "github.com/myorg/api/v2/middleware"}:              false,
+		{"github.com/myorg/*/*", "github.com/myorg/api/middleware/auth"}:                     true,
+		{"github.com/myorg/mw?", "github.com/myorg/mw2"}:                                     true,
+		{"github.com/myorg/[", "github.com/myorg/a"}:                                         false,
+		{"example.com/app [example.com/app.test]", "example.com/app [example.com/app.test]"}: true,
+	}
+	for m, expected := range tests {
+		if got := packageMatches(m.entry, m.packageName); got != expected {
+			t.Errorf("packageMatches(%q, %q), expected %v got %v", m.entry, m.packageName, expected, got)
+		}
+	}
+}
+
+func TestACaller_indexIgnoreLists(t *testing.T) {
+	var c ACaller
+	c.IgnorePackageNamed("example.com/lib")
+	c.IgnorePackageNamed("example.com/mw/*")
+	c.IgnoreFunctionReason("example.com/app.Log", "logger")
+	// copies must not see the changes made to the original
+	copied := c
+	c.IgnoreFunctionReason("example.com/app.Info", "logger")
+	c.UnignorePackage("example.com/lib")
+
+	if !copied.ignorePackageSet.has("example.com/lib") {
+		t.Errorf("copy package set, expected example.com/lib got %v", copied.ignorePackageSet)
+	}
+	if copied.ignoreFunctionSet.has("example.com/app.Info") {
+		t.Errorf("copy function set, expected no example.com/app.Info got %v", copied.ignoreFunctionSet)
+	}
+	if c.ignorePackageSet.has("example.com/lib") {
+		t.Errorf("package set, expected no example.com/lib got %v", c.ignorePackageSet)
+	}
+	if !reflect.DeepEqual(c.ignorePackagePatterns, []string{"example.com/mw/*"}) {
+		t.Errorf("patterns expected [example.com/mw/*] got %v", c.ignorePackagePatterns)
+	}
+	for _, function := range []string{"example.com/app.Log", "example.com/app.Info", "example.com/mw/auth.Check"} {
+		if !c.skipFrame(runtime.Frame{Function: function}) {
+			t.Errorf("%v ignored, expected true got false", function)
+		}
+	}
+}
+
+// linearIgnored is the ignore lookup made by scanning the ignore lists, for comparison with the sets.
+func linearIgnored(c *ACaller, packageName, functionName string) bool {
+	for _, pkgName := range c.ignorePackages {
+		if packageName == pkgName {
+			return true
+		}
+	}
+	for _, fnName := range c.ignoreFunctions {
+		if functionName == fnName {
+			return true
+		}
+	}
+	return false
+}
+
+func BenchmarkACaller_ignored(b *testing.B) {
+	for _, size := range []int{1, 10, 100} {
+		var c ACaller
+		for i := 0; i < size; i++ {
+			c.IgnorePackageNamed("example.com/pkg" + strconv.Itoa(i))
+			c.IgnoreFunctionReason("example.com/app.fn"+strconv.Itoa(i), "bench")
+		}
+		// a frame that is kept has to be checked against every entry
+		frame := runtime.Frame{Function: "example.com/main.main"}
+		packageName := PackageName(frame.Function)
+		b.Run("linear/"+strconv.Itoa(size), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				linearIgnored(&c, packageName, frame.Function)
+			}
+		})
+		b.Run("set/"+strconv.Itoa(size), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				c.ignored(frame, packageName, frame.Function)
+			}
+		})
+	}
+}
+
+func TestAddSelfPackageAlias(t *testing.T) {
+	const alias = "example.com/thirdparty/caller"
+	aliases, _ := selfAliases.Load().([]string)
+	defer func() {
+		selfAliases.Store(aliases)
+		resetClassCache()
+	}()
+	var c ACaller
+	fork := runtime.Frame{Function: alias + ".Caller"}
+	if c.IsIgnored(fork) {
+		t.Errorf("ignored %v before the alias, expected false got true", fork.Function)
+	}
+	AddSelfPackageAlias(alias)
+	AddSelfPackageAlias(alias)
+	if !c.IsIgnored(fork) {
+		t.Errorf("ignored %v, expected true got false", fork.Function)
+	}
+	if !RemoveSelfPackageAlias(alias) {
+		t.Errorf("removed, expected true got false")
+	}
+	if RemoveSelfPackageAlias(alias) {
+		t.Errorf("removed twice, expected false got true")
+	}
+	if c.IsIgnored(fork) {
+		t.Errorf("ignored %v after removing the alias, expected false got true", fork.Function)
+	}
+}
@@ -1,7 +1,12 @@
 package caller_test
 
 import (
+	"path"
+	"reflect"
+	"regexp"
 	"runtime"
+	"sort"
+	"strconv"
 	"strings"
 	"testing"
 
@@ -80,11 +85,1505 @@ func TestPackageName(t *testing.T) {
 	}
 	tests := map[string]string{
 		"github.com/gdey/caller_test.TestCaller_Caller.func1.10": "github.com/gdey/caller_test",
-		"runtime.Caller":  "runtime",
-		"Caller":          "",
-		"gdey/caller.Foo": "gdey/caller",
+		"runtime.Caller":                     "runtime",
+		"Caller":                             "",
+		"gdey/caller.Foo":                    "gdey/caller",
+		"gdey/caller [gdey/caller.test].Foo": "gdey/caller [gdey/caller.test]",
 	}
 	for fnName, pkgName := range tests {
 		t.Run(fn(fnName, pkgName))
 	}
 }
+
+func budgetInner(c caller.ACaller, budget int) (runtime.Frame, bool) {
+	return c.CallerWithinBudget(budget)
+}
+func budgetOuter(c caller.ACaller, budget int) (runtime.Frame, bool) { return budgetInner(c, budget) }
+
+func TestCaller_CallerWithinBudget(t *testing.T) {
+	const expectedName = "github.com/gdey/caller_test.TestCaller_CallerWithinBudget"
+
+	t.Run("enough budget", func(t *testing.T) {
+		var c caller.ACaller
+		frame, ok := budgetInner(c, caller.DefaultNumberOfFramesToGet)
+		if !ok {
+			t.Fatalf("ok, expected true got false")
+		}
+		if !strings.HasPrefix(frame.Function, expectedName) {
+			t.Errorf("frame expected '%v' got '%v'", expectedName, frame.Function)
+		}
+	})
+	t.Run("budget too small", func(t *testing.T) {
+		var c caller.ACaller
+		// ignore this package, so the nearest frame is testing.tRunner which is three frames away.
+		c.IgnorePackage()
+		if frame, ok := budgetOuter(c, 1); ok {
+			t.Errorf("ok, expected false got true; frame %v", frame.Function)
+		}
+		frame, ok := budgetOuter(c, 3)
+		if !ok {
+			t.Fatalf("ok, expected true got false")
+		}
+		if frame.Function != "testing.tRunner" {
+			t.Errorf("frame expected 'testing.tRunner' got '%v'", frame.Function)
+		}
+	})
+	t.Run("zero budget", func(t *testing.T) {
+		var c caller.ACaller
+		if _, ok := c.CallerWithinBudget(0); ok {
+			t.Errorf("ok, expected false got true")
+		}
+	})
+}
+
+func canonicalHelper(c caller.ACaller) runtime.Frame { return callerOf(c) }
+func callerOf(c caller.ACaller) runtime.Frame        { return c.Caller() }
+
+func TestACaller_SetNameCanonicalizer(t *testing.T) {
+	const expectedName = "github.com/gdey/caller_test.TestACaller_SetNameCanonicalizer"
+
+	t.Run("without canonicalizer", func(t *testing.T) {
+		var c caller.ACaller
+		c.IgnoreFunction("CANONICALHELPER")
+		frame := canonicalHelper(c)
+		if frame.Function != "github.com/gdey/caller_test.canonicalHelper" {
+			t.Errorf("frame expected 'github.com/gdey/caller_test.canonicalHelper' got '%v'", frame.Function)
+		}
+	})
+	t.Run("lower case canonicalizer", func(t *testing.T) {
+		var c caller.ACaller
+		c.SetNameCanonicalizer(strings.ToLower)
+		c.IgnoreFunction("CANONICALHELPER")
+		frame := canonicalHelper(c)
+		if !strings.HasPrefix(frame.Function, expectedName) {
+			t.Errorf("frame expected '%v' got '%v'", expectedName, frame.Function)
+		}
+	})
+	t.Run("set after ignore", func(t *testing.T) {
+		var c caller.ACaller
+		c.IgnoreFunction("CANONICALHELPER")
+		c.SetNameCanonicalizer(strings.ToLower)
+		frame := canonicalHelper(c)
+		if !strings.HasPrefix(frame.Function, expectedName) {
+			t.Errorf("frame expected '%v' got '%v'", expectedName, frame.Function)
+		}
+	})
+	t.Run("copies are not changed", func(t *testing.T) {
+		var c caller.ACaller
+		c.IgnorePackageNamed("example.com/lib")
+		c.IgnorePackagePrefix("example.com/mw/")
+		c.IgnoreFunctionReason("example.com/app.Log", "logger")
+		copied := c
+		c.SetNameCanonicalizer(strings.ToUpper)
+		for _, name := range []string{"example.com/lib.Func", "example.com/mw/auth.Check", "example.com/app.Log"} {
+			if !copied.IsIgnored(runtime.Frame{Function: name}) {
+				t.Errorf("copy ignored %v, expected true got false", name)
+			}
+		}
+		expected := []string{"example.com/lib"}
+		if got := copied.IgnoredPackages(); !reflect.DeepEqual(got, expected) {
+			t.Errorf("copy ignored packages expected %v got %v", expected, got)
+		}
+	})
+}
+
+func TestACaller_CallerOK(t *testing.T) {
+	const expectedName = "github.com/gdey/caller_test.TestACaller_CallerOK"
+
+	t.Run("found", func(t *testing.T) {
+		var c caller.ACaller
+		frame, ok := func() (runtime.Frame, bool) { return c.CallerOK() }()
+		if !ok {
+			t.Fatalf("ok, expected true got false")
+		}
+		if !strings.HasPrefix(frame.Function, expectedName) {
+			t.Errorf("frame expected '%v' got '%v'", expectedName, frame.Function)
+		}
+	})
+	t.Run("goexit is never returned", func(t *testing.T) {
+		type result struct {
+			frame    runtime.Frame
+			callerFn string
+			ok       bool
+		}
+		var c caller.ACaller
+		// ignore all frames in this package; in a new goroutine only runtime.goexit is left on the stack.
+		c.IgnorePackage()
+		done := make(chan result)
+		go func() {
+			var res result
+			res.frame, res.ok = func() (runtime.Frame, bool) { return c.CallerOK() }()
+			res.callerFn = func() runtime.Frame { return c.Caller() }().Function
+			done <- res
+		}()
+		res := <-done
+		if res.ok {
+			t.Errorf("ok, expected false got true")
+		}
+		if res.frame.Function != "" {
+			t.Errorf("frame expected zero frame got '%v'", res.frame.Function)
+		}
+		if res.callerFn != "" {
+			t.Errorf("caller expected zero frame got '%v'", res.callerFn)
+		}
+	})
+}
+
+func TestACaller_SetAllowedModules(t *testing.T) {
+	const expectedName = "github.com/gdey/caller_test.TestACaller_SetAllowedModules"
+
+	tests := map[string]struct {
+		allowed  []string
+		expected string
+	}{
+		"all allowed":      {expected: expectedName},
+		"this module":      {allowed: []string{"github.com/gdey/"}, expected: expectedName},
+		"only testing":     {allowed: []string{"testing"}, expected: "testing.tRunner"},
+		"first of several": {allowed: []string{"example.com/", "testing"}, expected: "testing.tRunner"},
+	}
+	for name, tc := range tests {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			var c caller.ACaller
+			c.SetAllowedModules(tc.allowed...)
+			frame := callerOf(c)
+			if !strings.HasPrefix(frame.Function, tc.expected) {
+				t.Errorf("frame expected '%v' got '%v'", tc.expected, frame.Function)
+			}
+		})
+	}
+}
+
+func TestACaller_CallerWithSource(t *testing.T) {
+	const expectedName = "github.com/gdey/caller_test.TestACaller_CallerWithSource"
+	var c caller.ACaller
+	frame, ok := func() (runtime.Frame, bool) { return c.CallerWithSource() }()
+	if !ok {
+		t.Fatalf("ok, expected true got false")
+	}
+	if !strings.HasPrefix(frame.Function, expectedName) {
+		t.Errorf("frame expected '%v' got '%v'", expectedName, frame.Function)
+	}
+	if !strings.HasSuffix(frame.File, "caller_test.go") {
+		t.Errorf("file expected 'caller_test.go' got '%v'", frame.File)
+	}
+}
+
+func TestACaller_IgnoreFunctionReason(t *testing.T) {
+	const expectedName = "github.com/gdey/caller_test.TestACaller_IgnoreFunctionReason"
+	var c caller.ACaller
+	c.IgnoreFunctionReason("github.com/gdey/caller_test.canonicalHelper", "wraps callerOf")
+	c.IgnoreFunctionReason("example.com/log.Info", "logging facade")
+	c.IgnoreFunctionReason("example.com/log.Info", "our logging facade")
+
+	expected := map[string]string{
+		"github.com/gdey/caller_test.canonicalHelper": "wraps callerOf",
+		"example.com/log.Info":                        "our logging facade",
+	}
+	reasons := c.IgnoreReasons()
+	if !reflect.DeepEqual(reasons, expected) {
+		t.Errorf("reasons expected %v got %v", expected, reasons)
+	}
+	// modifying the returned map should not change the callers reasons
+	delete(reasons, "example.com/log.Info")
+	if len(c.IgnoreReasons()) != 2 {
+		t.Errorf("reasons expected copy to be returned")
+	}
+	frame := canonicalHelper(c)
+	if !strings.HasPrefix(frame.Function, expectedName) {
+		t.Errorf("frame expected '%v' got '%v'", expectedName, frame.Function)
+	}
+
+	// copies of the caller do not see each others reasons
+	copied := c
+	copied.IgnoreFunctionReason("example.com/log.Warn", "copy")
+	if _, ok := c.IgnoreReasons()["example.com/log.Warn"]; ok {
+		t.Errorf("reasons of the copy, expected not to be seen by the original")
+	}
+
+	// the reason is removed with the function; and is not there when the function is ignored again
+	if !c.UnignoreFunction("canonicalHelper") {
+		t.Fatalf("unignore, expected true got false")
+	}
+	c.IgnoreFunction("canonicalHelper")
+	if reason, ok := c.IgnoreReasons()["github.com/gdey/caller_test.canonicalHelper"]; ok {
+		t.Errorf("reason after unignore, expected none got '%v'", reason)
+	}
+	if _, ok := copied.IgnoreReasons()["github.com/gdey/caller_test.canonicalHelper"]; !ok {
+		t.Errorf("reason of the copy, expected it to survive the unignore of the original")
+	}
+}
+
+func lineNumberOf(c caller.ACaller) (int, bool) { return c.CallerLineNumber() }
+
+func TestACaller_CallerLineNumber(t *testing.T) {
+	var c caller.ACaller
+	_, _, expected, _ := runtime.Caller(0)
+	line, ok := lineNumberOf(c)
+	if !ok {
+		t.Fatalf("ok, expected true got false")
+	}
+	if line != expected+1 {
+		t.Errorf("line expected %v got %v", expected+1, line)
+	}
+}
+
+func TestACaller_LoadIgnoreFunctions(t *testing.T) {
+	const expectedName = "github.com/gdey/caller_test.TestACaller_LoadIgnoreFunctions"
+	const list = `# functions that wrap the caller
+github.com/gdey/caller_test.canonicalHelper
+
+  example.com/log.Info   # the info logger
+# a duplicate is only counted once
+example.com/log.Info
+`
+	var c caller.ACaller
+	count, err := c.LoadIgnoreFunctions(strings.NewReader(list))
+	if err != nil {
+		t.Fatalf("error, expected nil got %v", err)
+	}
+	if count != 2 {
+		t.Errorf("count expected 2 got %v", count)
+	}
+	frame := canonicalHelper(c)
+	if !strings.HasPrefix(frame.Function, expectedName) {
+		t.Errorf("frame expected '%v' got '%v'", expectedName, frame.Function)
+	}
+}
+
+func BenchmarkACaller_LoadIgnoreFunctions(b *testing.B) {
+	for _, size := range []int{1000, 5000, 20000} {
+		var list strings.Builder
+		for i := 0; i < size; i++ {
+			list.WriteString("example.com/app.fn" + strconv.Itoa(i) + "\n")
+		}
+		names := list.String()
+		b.Run(strconv.Itoa(size), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				var c caller.ACaller
+				if _, err := c.LoadIgnoreFunctions(strings.NewReader(names)); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+func TestACaller_SetFilterMode(t *testing.T) {
+	const expectedName = "github.com/gdey/caller_test.TestACaller_SetFilterMode"
+
+	tests := map[string]struct {
+		mode     caller.FilterMode
+		expected string
+	}{
+		// the ignore lists refine the allowed modules, so canonicalHelper is skipped
+		"allow then deny": {mode: caller.AllowThenDeny, expected: expectedName},
+		// the allowed modules override the ignore lists, so canonicalHelper is kept
+		"deny then allow": {mode: caller.DenyThenAllow, expected: "github.com/gdey/caller_test.canonicalHelper"},
+	}
+	for name, tc := range tests {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			var c caller.ACaller
+			c.SetFilterMode(tc.mode)
+			c.SetAllowedModules("github.com/gdey/caller_test")
+			c.IgnoreFunction("canonicalHelper")
+			frame := canonicalHelper(c)
+			if !strings.HasPrefix(frame.Function, tc.expected) {
+				t.Errorf("frame expected '%v' got '%v'", tc.expected, frame.Function)
+			}
+		})
+	}
+}
+
+func TestACaller_CallerHint(t *testing.T) {
+	const senderName = "github.com/gdey/caller_test.TestACaller_CallerHint.func1"
+	var c caller.ACaller
+	// ignore this package, so the receiver, which runs in it's own goroutine has nothing on it's stack.
+	c.IgnorePackage()
+
+	var (
+		work   = make(chan runtime.Frame)
+		result = make(chan runtime.Frame)
+	)
+	go func() {
+		var sender caller.ACaller
+		work <- func() runtime.Frame { return sender.CallerHint() }()
+	}()
+	go func() {
+		hint := <-work
+		result <- func() runtime.Frame { return c.CallerHint(hint) }()
+	}()
+	frame := <-result
+	if frame.Function != senderName {
+		t.Errorf("frame expected '%v' got '%v'", senderName, frame.Function)
+	}
+}
+
+func samePackageAs(c caller.ACaller, fullFuncName string) bool {
+	return c.CallerInSamePackageAs(fullFuncName)
+}
+
+func TestACaller_CallerInSamePackageAs(t *testing.T) {
+	tests := map[string]struct {
+		reference    string
+		expected     bool
+		canonicalize func(string) string
+	}{
+		"same package":  {reference: "github.com/gdey/caller_test.callCaller", expected: true},
+		"other package": {reference: "github.com/gdey/caller/simple/log.Caller"},
+		"prefix only":   {reference: "github.com/gdey/caller.Caller"},
+		"vendored name": {
+			reference:    "example.com/app/vendor/github.com/gdey/caller_test.callCaller",
+			canonicalize: stripVendor,
+			expected:     true,
+		},
+	}
+	for name, tc := range tests {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			var c caller.ACaller
+			c.SetNameCanonicalizer(tc.canonicalize)
+			if got := samePackageAs(c, tc.reference); got != tc.expected {
+				t.Errorf("same package, expected %v got %v", tc.expected, got)
+			}
+		})
+	}
+}
+
+func fingerprintOf(c caller.ACaller) string { return c.CallerFingerprint() }
+
+func TestACaller_CallerFingerprint(t *testing.T) {
+	var (
+		c            caller.ACaller
+		fingerprints []string
+	)
+	for i := 0; i < 2; i++ {
+		fingerprints = append(fingerprints, fingerprintOf(c))
+	}
+	_, _, line, _ := runtime.Caller(0)
+	other := fingerprintOf(c)
+
+	expected := "github.com/gdey/caller_test.TestACaller_CallerFingerprint:" + strconv.Itoa(line-2)
+	if fingerprints[0] != expected {
+		t.Errorf("fingerprint expected '%v' got '%v'", expected, fingerprints[0])
+	}
+	if fingerprints[0] != fingerprints[1] {
+		t.Errorf("fingerprint expected same line to match, got '%v' and '%v'", fingerprints[0], fingerprints[1])
+	}
+	if other == fingerprints[0] {
+		t.Errorf("fingerprint expected different lines to differ, got '%v' for both", other)
+	}
+}
+
+type concreteWherer interface {
+	Where() (runtime.Frame, bool)
+}
+
+type concreteValue struct{ c caller.ACaller }
+
+func concreteOf(c caller.ACaller) (runtime.Frame, bool) { return c.CallerConcrete() }
+
+func (v concreteValue) Where() (runtime.Frame, bool) { return concreteOf(v.c) }
+
+func TestACaller_CallerConcrete(t *testing.T) {
+	const expectedName = "github.com/gdey/caller_test.concreteValue.Where"
+	// a pointer to a value receiver, needs the compiler to generate a wrapper for the interface
+	var w concreteWherer = &concreteValue{}
+	frame, ok := w.Where()
+	if !ok {
+		t.Fatalf("ok, expected true got false")
+	}
+	if frame.Function != expectedName {
+		t.Errorf("frame expected '%v' got '%v'", expectedName, frame.Function)
+	}
+}
+
+func exceptOf(c caller.ACaller, extra ...string) (runtime.Frame, bool) {
+	return c.CallerExcept(extra...)
+}
+func exceptOuter(c caller.ACaller, extra ...string) (runtime.Frame, bool) {
+	return exceptOf(c, extra...)
+}
+
+func TestACaller_CallerExcept(t *testing.T) {
+	const expectedName = "github.com/gdey/caller_test.TestACaller_CallerExcept"
+	tests := map[string]struct {
+		extra    []string
+		expected string
+	}{
+		"no extra":    {expected: "github.com/gdey/caller_test.exceptOuter"},
+		"extra":       {extra: []string{"github.com/gdey/caller_test.exceptOuter"}, expected: expectedName},
+		"not matched": {extra: []string{"exceptOuter"}, expected: "github.com/gdey/caller_test.exceptOuter"},
+	}
+	for name, tc := range tests {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			var c caller.ACaller
+			frame, ok := exceptOuter(c, tc.extra...)
+			if !ok {
+				t.Fatalf("ok, expected true got false")
+			}
+			if !strings.HasPrefix(frame.Function, tc.expected) {
+				t.Errorf("frame expected '%v' got '%v'", tc.expected, frame.Function)
+			}
+			// the extra ignores should not change the caller
+			if frame = canonicalHelper(c); frame.Function != "github.com/gdey/caller_test.canonicalHelper" {
+				t.Errorf("frame expected 'github.com/gdey/caller_test.canonicalHelper' got '%v'", frame.Function)
+			}
+		})
+	}
+}
+
+func locationOf(c caller.ACaller) (string, string, int, bool) { return c.CallerLocation() }
+
+func TestACaller_CallerLocation(t *testing.T) {
+	var c caller.ACaller
+	pc, expectedFile, expectedLine, _ := runtime.Caller(0)
+	function, file, line, ok := locationOf(c)
+	if !ok {
+		t.Fatalf("ok, expected true got false")
+	}
+	if expected := runtime.FuncForPC(pc).Name(); function != expected {
+		t.Errorf("function expected '%v' got '%v'", expected, function)
+	}
+	if file != expectedFile {
+		t.Errorf("file expected '%v' got '%v'", expectedFile, file)
+	}
+	if line != expectedLine+1 {
+		t.Errorf("line expected %v got %v", expectedLine+1, line)
+	}
+}
+
+func TestACaller_IgnoreForwarders(t *testing.T) {
+	const expectedName = "github.com/gdey/caller_test.TestACaller_IgnoreForwarders"
+	var c caller.ACaller
+	c.IgnoreForwarders()
+
+	// assigning the closures to variables, keeps them from being inlined.
+	var forwarder, notForwarder func() runtime.Frame
+	forwarder = func() runtime.Frame { return callerOf(c) }
+	notForwarder = func() runtime.Frame {
+		return callerOf(c)
+	}
+
+	if frame := forwarder(); frame.Function != expectedName {
+		t.Errorf("frame expected '%v' got '%v'", expectedName, frame.Function)
+	}
+	if frame := notForwarder(); frame.Function != expectedName+".func2" {
+		t.Errorf("frame expected '%v' got '%v'", expectedName+".func2", frame.Function)
+	}
+}
+
+// assertCalled mimics an assertion, that reports the caller of the helper that called it.
+func assertCalled(c caller.ACaller) (runtime.Frame, bool) { return c.CallerOfCaller() }
+
+// checkCalled mimics a helper that uses an assertion.
+func checkCalled(c caller.ACaller) (runtime.Frame, bool) { return assertCalled(c) }
+
+func TestACaller_CallerOfCaller(t *testing.T) {
+	const expectedName = "github.com/gdey/caller_test.TestACaller_CallerOfCaller"
+	var c caller.ACaller
+	frame, ok := checkCalled(c)
+	if !ok {
+		t.Fatalf("ok, expected true got false")
+	}
+	if frame.Function != expectedName {
+		t.Errorf("frame expected '%v' got '%v'", expectedName, frame.Function)
+	}
+}
+
+func callerAtOf(c caller.ACaller, n int) (runtime.Frame, bool) { return c.CallerAt(n) }
+
+func TestACaller_CallerAt(t *testing.T) {
+	const expectedName = "github.com/gdey/caller_test.TestACaller_CallerAt"
+	var c caller.ACaller
+	tests := map[int]string{
+		0: expectedName + ".func1",
+		1: expectedName,
+		2: "testing.tRunner",
+	}
+	for n, expected := range tests {
+		frame, ok := func() (runtime.Frame, bool) { return callerAtOf(c, n) }()
+		if !ok {
+			t.Errorf("ok for %v, expected true got false", n)
+			continue
+		}
+		if frame.Function != expected {
+			t.Errorf("frame for %v expected '%v' got '%v'", n, expected, frame.Function)
+		}
+	}
+	if _, ok := callerAtOf(c, -1); ok {
+		t.Errorf("ok for -1, expected false got true")
+	}
+}
+
+func TestACaller_CallerWithLine(t *testing.T) {
+	const expectedName = "github.com/gdey/caller_test.TestACaller_CallerWithLine"
+	var c caller.ACaller
+	frame, ok := func() (runtime.Frame, bool) { return c.CallerWithLine() }()
+	if !ok {
+		t.Fatalf("ok, expected true got false")
+	}
+	if frame.Function != expectedName || frame.Line == 0 {
+		t.Errorf("frame expected '%v' with a line got '%v:%v'", expectedName, frame.Function, frame.Line)
+	}
+}
+
+func TestACaller_IgnorePackageWithTests(t *testing.T) {
+	t.Run("external test package", func(t *testing.T) {
+		var c caller.ACaller
+		c.IgnorePackageWithTests()
+		if frame := callerOf(c); frame.Function != "testing.tRunner" {
+			t.Errorf("frame expected 'testing.tRunner' got '%v'", frame.Function)
+		}
+		self := runtime.Frame{Function: "github.com/gdey/caller.Caller"}
+		if !c.IsIgnored(self) {
+			t.Errorf("ignored %v, expected true got false", self.Function)
+		}
+	})
+	t.Run("package", func(t *testing.T) {
+		c := log.WithTests()
+		for _, name := range []string{
+			"github.com/gdey/caller/simple/log.Caller",
+			"github.com/gdey/caller/simple/log_test.TestCaller",
+			"github.com/gdey/caller/simple/log [github.com/gdey/caller/simple/log.test].Caller",
+		} {
+			if !c.IsIgnored(runtime.Frame{Function: name}) {
+				t.Errorf("ignored %v, expected true got false", name)
+			}
+		}
+		if frame := callerOf(c.ACaller); !strings.HasPrefix(frame.Function, "github.com/gdey/caller_test.") {
+			t.Errorf("frame expected to be in 'github.com/gdey/caller_test' got '%v'", frame.Function)
+		}
+	})
+}
+
+func anchorDispatch(fn func() (runtime.Frame, bool)) (runtime.Frame, bool) { return fn() }
+func anchorOf(c caller.ACaller) (runtime.Frame, bool)                      { return c.CallerFromAnchor() }
+
+func TestACaller_CallerFromAnchor(t *testing.T) {
+	const expectedName = "github.com/gdey/caller_test.TestACaller_CallerFromAnchor.func1"
+	var c caller.ACaller
+	if _, ok := anchorOf(c); ok {
+		t.Errorf("ok without an anchor, expected false got true")
+	}
+	c.SetAnchor("github.com/gdey/caller_test.anchorDispatch")
+	c.IgnoreFunction("anchorDispatch")
+	// the stack crosses the anchor twice, the inner most one should be used.
+	frame, ok := anchorDispatch(func() (runtime.Frame, bool) {
+		return anchorDispatch(func() (runtime.Frame, bool) { return anchorOf(c) })
+	})
+	if !ok {
+		t.Fatalf("ok, expected true got false")
+	}
+	if frame.Function != expectedName {
+		t.Errorf("frame expected '%v' got '%v'", expectedName, frame.Function)
+	}
+	if _, ok := anchorOf(c); ok {
+		t.Errorf("ok without the anchor on the stack, expected false got true")
+	}
+}
+
+func regexpOf(c caller.ACaller, re *regexp.Regexp) (runtime.Frame, bool) {
+	return c.CallerMatchingRegexp(re)
+}
+func regexpB(c caller.ACaller, re *regexp.Regexp) (runtime.Frame, bool) { return regexpOf(c, re) }
+func regexpA(c caller.ACaller, re *regexp.Regexp) (runtime.Frame, bool) { return regexpB(c, re) }
+
+func TestACaller_CallerMatchingRegexp(t *testing.T) {
+	var c caller.ACaller
+	tests := map[string]struct {
+		re       string
+		expected string
+		ok       bool
+	}{
+		"innermost of two": {re: `caller_test\.regexp[AB]$`, expected: "github.com/gdey/caller_test.regexpB", ok: true},
+		"outer":            {re: `caller_test\.regexpA$`, expected: "github.com/gdey/caller_test.regexpA", ok: true},
+		"no match":         {re: `^example\.com/`},
+	}
+	for name, tc := range tests {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			frame, ok := regexpA(c, regexp.MustCompile(tc.re))
+			if ok != tc.ok {
+				t.Fatalf("ok, expected %v got %v", tc.ok, ok)
+			}
+			if frame.Function != tc.expected {
+				t.Errorf("frame expected '%v' got '%v'", tc.expected, frame.Function)
+			}
+		})
+	}
+}
+
+func TestACaller_SetUnifyMethodForms(t *testing.T) {
+	const pkg = "github.com/gdey/caller_test."
+	forms := []string{
+		pkg + "unifyLog.Info",
+		pkg + "(*unifyLog).Info",
+		pkg + "(*unifyLog).Info-fm",
+		pkg + "unifyLog.Info-fm",
+	}
+	tests := map[string]struct {
+		unify    bool
+		expected []bool
+	}{
+		"not unified": {expected: []bool{true, false, false, false}},
+		"unified":     {unify: true, expected: []bool{true, true, true, true}},
+	}
+	for name, tc := range tests {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			var c caller.ACaller
+			c.IgnoreFunction("unifyLog.Info")
+			c.SetUnifyMethodForms(tc.unify)
+			for i, form := range forms {
+				if got := c.IsIgnored(runtime.Frame{Function: form}); got != tc.expected[i] {
+					t.Errorf("ignored %v, expected %v got %v", form, tc.expected[i], got)
+				}
+			}
+			other := runtime.Frame{Function: pkg + "(*unifyLog).Warn"}
+			if c.IsIgnored(other) {
+				t.Errorf("ignored %v, expected false got true", other.Function)
+			}
+		})
+	}
+}
+
+func TestACaller_CallerAcrossPackageBoundary(t *testing.T) {
+	const expectedName = "github.com/gdey/caller_test.TestACaller_CallerAcrossPackageBoundary"
+	frame, ok := log.Boundary()
+	if !ok {
+		t.Fatalf("ok, expected true got false")
+	}
+	if frame.Function != expectedName {
+		t.Errorf("frame expected '%v' got '%v'", expectedName, frame.Function)
+	}
+}
+
+func TestACaller_IgnoreKnownLoggers(t *testing.T) {
+	loggers := []string{
+		"log.Printf",
+		"log/slog.(*Logger).Info",
+		"github.com/sirupsen/logrus.(*Entry).Info",
+		"go.uber.org/zap.(*Logger).Info",
+		"go.uber.org/zap/zapcore.(*CheckedEntry).Write",
+		"github.com/rs/zerolog.(*Event).Msg",
+		"github.com/rs/zerolog/log.Info",
+		"k8s.io/klog.Infof",
+		"k8s.io/klog/v2.Infof",
+	}
+	var c caller.ACaller
+	c.IgnoreKnownLoggers()
+	for _, name := range loggers {
+		if !c.IsIgnored(runtime.Frame{Function: name}) {
+			t.Errorf("ignored %v, expected true got false", name)
+		}
+	}
+	user := runtime.Frame{Function: "example.com/app.main"}
+	if c.IsIgnored(user) {
+		t.Errorf("ignored %v, expected false got true", user.Function)
+	}
+}
+
+func TestACaller_IgnorePackageNamed(t *testing.T) {
+	const expectedName = "github.com/gdey/caller_test.TestACaller_IgnorePackageNamed"
+	var c caller.ACaller
+	c.IgnorePackageNamed("testing")
+	frame := func() runtime.Frame { return callerOf(c) }()
+	if !strings.HasPrefix(frame.Function, expectedName) {
+		t.Errorf("frame expected '%v' got '%v'", expectedName, frame.Function)
+	}
+	c.IgnorePackageNamed("github.com/gdey/caller_test")
+	done := make(chan runtime.Frame)
+	go func() { done <- callerOf(c) }()
+	if frame := <-done; frame.Function != "" {
+		t.Errorf("frame expected zero frame got '%v'", frame.Function)
+	}
+}
+
+func userCodeOf(c caller.ACaller) (runtime.Frame, bool) { return c.CallerUserCode() }
+
+// userCodeThroughSort calls userCodeOf from within the less function of sort.Slice, putting standard library
+// frames between the less function and userCodeThroughSort.
+func userCodeThroughSort(c caller.ACaller) (frame runtime.Frame, ok bool) {
+	values := []int{2, 1}
+	sort.Slice(values, func(i, j int) bool {
+		frame, ok = userCodeOf(c)
+		return values[i] < values[j]
+	})
+	return frame, ok
+}
+
+func TestACaller_CallerUserCode(t *testing.T) {
+	const (
+		expectedName = "github.com/gdey/caller_test.userCodeThroughSort"
+	)
+	var c caller.ACaller
+	c.IgnoreFunction("userCodeThroughSort.func1")
+	frame, ok := userCodeThroughSort(c)
+	if !ok {
+		t.Fatalf("ok, expected true got false")
+	}
+	if frame.Function != expectedName {
+		t.Errorf("frame expected '%v' got '%v'", expectedName, frame.Function)
+	}
+
+}
+
+func ruleStatsOf(c caller.ACaller) (runtime.Frame, int, bool) { return c.CallerWithRuleStats() }
+
+func TestACaller_CallerWithRuleStats(t *testing.T) {
+	var c caller.ACaller
+	c.IgnoreFunctionReason("example.com/app.one", "test")
+	c.IgnoreFunctionReason("example.com/app.two", "test")
+	c.IgnoreFunctionReason("example.com/app.three", "test")
+	c.IgnorePackageNamed("example.com/other")
+	c.IgnorePackageNamed("example.com/another")
+	c.SetAllowedModules("example.com", "github.com/gdey")
+	c.AddInfoFilter(func(caller.CallerInfo) bool { return false })
+
+	frame, count, ok := ruleStatsOf(c)
+	if !ok {
+		t.Fatalf("ok, expected true got false")
+	}
+	const expectedName = "github.com/gdey/caller_test.TestACaller_CallerWithRuleStats"
+	if frame.Function != expectedName {
+		t.Errorf("frame expected '%v' got '%v'", expectedName, frame.Function)
+	}
+	// 1 filter, 2 packages, 3 functions and both allowed modules
+	if count != 8 {
+		t.Errorf("checked rules, expected 8 got %v", count)
+	}
+}
+
+func TestACaller_SetCheckOrder(t *testing.T) {
+	frames := []runtime.Frame{
+		{Function: "example.com/app.one"},
+		{Function: "example.com/app.two"},
+		{Function: "example.com/other.(*Log).Info"},
+		{Function: "example.com/lib.Func"},
+		{Function: "example.com/app.main"},
+	}
+	orders := [][]caller.CheckKind{
+		nil,
+		{caller.CheckFunctions},
+		{caller.CheckFunctions, caller.CheckPackages, caller.CheckInfoFilters},
+		{caller.CheckPackages, caller.CheckInfoFilters},
+	}
+	newCaller := func(order []caller.CheckKind) caller.ACaller {
+		var c caller.ACaller
+		c.IgnoreFunctionReason("example.com/app.one", "test")
+		c.IgnoreFunctionReason("example.com/app.two", "test")
+		c.IgnorePackageNamed("example.com/other")
+		c.AddInfoFilter(func(info caller.CallerInfo) bool { return info.Receiver == "Log" })
+		c.SetCheckOrder(order)
+		return c
+	}
+	expected := newCaller(nil)
+	for _, order := range orders {
+		c := newCaller(order)
+		for _, frame := range frames {
+			if got, want := c.IsIgnored(frame), expected.IsIgnored(frame); got != want {
+				t.Errorf("order %v ignored %v, expected %v got %v", order, frame.Function, want, got)
+			}
+		}
+	}
+}
+
+func BenchmarkACaller_SetCheckOrder(b *testing.B) {
+	var c caller.ACaller
+	for i := 0; i < 20; i++ {
+		c.IgnorePackageNamed("example.com/pkg" + strconv.Itoa(i))
+	}
+	for i := 0; i < 5; i++ {
+		c.IgnoreFunctionReason("example.com/app.fn"+strconv.Itoa(i), "bench")
+	}
+	frame := runtime.Frame{Function: "example.com/app.fn0"}
+	b.Run("default", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			c.IsIgnored(frame)
+		}
+	})
+	c.SetCheckOrder([]caller.CheckKind{caller.CheckFunctions})
+	b.Run("functions first", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			c.IsIgnored(frame)
+		}
+	})
+}
+
+func functionLineOf(c caller.ACaller) (string, int, bool) { return c.CallerFunctionLine() }
+
+func functionLineCaller(c caller.ACaller) (file string, declLine, expectedLine int, ok bool) {
+	_, _, line, _ := runtime.Caller(0)
+	file, declLine, ok = functionLineOf(c)
+	return file, declLine, line - 1, ok
+}
+
+func TestACaller_CallerFunctionLine(t *testing.T) {
+	var c caller.ACaller
+	file, declLine, expectedLine, ok := functionLineCaller(c)
+	if !ok {
+		t.Fatalf("ok, expected true got false")
+	}
+	if !strings.HasSuffix(file, "caller_test.go") {
+		t.Errorf("file expected 'caller_test.go' got '%v'", file)
+	}
+	if declLine != expectedLine {
+		t.Errorf("declaration line expected %v got %v", expectedLine, declLine)
+	}
+
+	// the declaration of an inlined caller is not known; the declaration of the function it was inlined into must
+	// not be returned in it's place
+	pc, _, _, _ := runtime.Caller(0)
+	test := runtime.FuncForPC(pc)
+	_, testLine := test.FileLine(test.Entry())
+	if _, declLine, ok := inlinedFunctionLineCaller(c); ok && declLine == testLine {
+		t.Errorf("inlined declaration line, expected not ok got the line of the test %v", declLine)
+	}
+}
+
+// inlinedFunctionLineCaller is small enough to be inlined into the test
+func inlinedFunctionLineCaller(c caller.ACaller) (string, int, bool) { return inlinedFunctionLineOf(c) }
+
+//go:noinline
+func inlinedFunctionLineOf(c caller.ACaller) (string, int, bool) { return c.CallerFunctionLine() }
+
+//go:noinline
+func approximateOf(c caller.ACaller) (runtime.Frame, bool) { return c.CallerApproximate() }
+
+//go:noinline
+func approximateRecurse(c caller.ACaller, depth int) (runtime.Frame, bool) {
+	if depth == 0 {
+		return approximateOf(c)
+	}
+	return approximateRecurse(c, depth-1)
+}
+
+func TestACaller_CallerApproximate(t *testing.T) {
+	var c caller.ACaller
+	t.Run("exact", func(t *testing.T) {
+		const expectedName = "github.com/gdey/caller_test.TestACaller_CallerApproximate.func1"
+		frame, exact := approximateOf(c)
+		if !exact {
+			t.Errorf("exact, expected true got false")
+		}
+		if frame.Function != expectedName {
+			t.Errorf("frame expected '%v' got '%v'", expectedName, frame.Function)
+		}
+	})
+	t.Run("deep stack", func(t *testing.T) {
+		const expectedName = "github.com/gdey/caller_test.TestACaller_CallerApproximate.func2"
+		c := c
+		c.IgnoreFunction("approximateRecurse")
+		frame, exact := approximateRecurse(c, caller.DefaultNumberOfFramesToGet*5)
+		if !exact {
+			t.Errorf("exact, expected true got false")
+		}
+		if frame.Function != expectedName {
+			t.Errorf("frame expected '%v' got '%v'", expectedName, frame.Function)
+		}
+	})
+}
+
+func pastReflectOf(c caller.ACaller) (runtime.Frame, bool) { return c.CallerPastReflect() }
+
+// reflectLogger is the function called through reflection; it is in the ignore list
+func reflectLogger(c caller.ACaller) (frame runtime.Frame, ok bool) {
+	frame, ok = pastReflectOf(c)
+	return frame, ok
+}
+
+func TestACaller_CallerPastReflect(t *testing.T) {
+	const expectedName = "github.com/gdey/caller_test.TestACaller_CallerPastReflect"
+	var c caller.ACaller
+	c.IgnoreFunction("reflectLogger")
+	results := reflect.ValueOf(reflectLogger).Call([]reflect.Value{reflect.ValueOf(c)})
+	frame, ok := results[0].Interface().(runtime.Frame), results[1].Bool()
+	if !ok {
+		t.Fatalf("ok, expected true got false")
+	}
+	if frame.Function != expectedName {
+		t.Errorf("frame expected '%v' got '%v'", expectedName, frame.Function)
+	}
+}
+
+func aboveMatchOf(c caller.ACaller, re *regexp.Regexp, levels int) (runtime.Frame, bool) {
+	return c.CallerAboveMatch(re, levels)
+}
+
+func aboveMatchHandler(c caller.ACaller, re *regexp.Regexp, levels int) (runtime.Frame, bool) {
+	return aboveMatchOf(c, re, levels)
+}
+
+func aboveMatchDispatch(c caller.ACaller, re *regexp.Regexp, levels int) (runtime.Frame, bool) {
+	return aboveMatchHandler(c, re, levels)
+}
+
+func TestACaller_CallerAboveMatch(t *testing.T) {
+	var c caller.ACaller
+	re := regexp.MustCompile(`\.aboveMatchDispatch$`)
+	tests := map[string]struct {
+		re           *regexp.Regexp
+		levels       int
+		expectedName string
+		ok           bool
+	}{
+		"match": {
+			re:           re,
+			expectedName: "github.com/gdey/caller_test.aboveMatchDispatch",
+			ok:           true,
+		},
+		"one level": {
+			re:           re,
+			levels:       1,
+			expectedName: "github.com/gdey/caller_test.TestACaller_CallerAboveMatch.func1",
+			ok:           true,
+		},
+		"no match": {
+			re:     regexp.MustCompile(`\.notOnTheStack$`),
+			levels: 1,
+		},
+	}
+	for name, tc := range tests {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			frame, ok := aboveMatchDispatch(c, tc.re, tc.levels)
+			if ok != tc.ok {
+				t.Fatalf("ok, expected %v got %v", tc.ok, ok)
+			}
+			if frame.Function != tc.expectedName {
+				t.Errorf("frame expected '%v' got '%v'", tc.expectedName, frame.Function)
+			}
+		})
+	}
+}
+
+func deepCallerOf(c caller.ACaller) (runtime.Frame, bool) { return c.CallerOK() }
+
+func deepRecurse(c caller.ACaller, depth int) (runtime.Frame, bool) {
+	if depth == 0 {
+		return deepCallerOf(c)
+	}
+	return deepRecurse(c, depth-1)
+}
+
+func TestACaller_GrowRetries(t *testing.T) {
+	c := caller.New(caller.WithIgnoredFunctions("github.com/gdey/caller_test.deepRecurse"))
+	total := caller.GrowRetries()
+	if _, ok := deepRecurse(*c, 0); !ok {
+		t.Fatalf("shallow ok, expected true got false")
+	}
+	if retries := c.GrowRetries(); retries != 0 {
+		t.Errorf("shallow retries, expected 0 got %v", retries)
+	}
+	// 15 frames, then 30, then 60
+	if _, ok := deepRecurse(*c, caller.DefaultNumberOfFramesToGet*2); !ok {
+		t.Fatalf("deep ok, expected true got false")
+	}
+	if retries := c.GrowRetries(); retries == 0 {
+		t.Errorf("deep retries, expected more then 0 got 0")
+	}
+	if retries := caller.GrowRetries() - total; retries < c.GrowRetries() {
+		t.Errorf("total retries, expected at least %v got %v", c.GrowRetries(), retries)
+	}
+	// callers that are not built with New do not keep a count
+	var plain caller.ACaller
+	if retries := plain.GrowRetries(); retries != 0 {
+		t.Errorf("zero value retries, expected 0 got %v", retries)
+	}
+}
+
+func deepExceptRecurse(c caller.ACaller, depth int) (runtime.Frame, bool) {
+	if depth == 0 {
+		return c.CallerExcept("github.com/gdey/caller_test.deepExceptRecurse")
+	}
+	return deepExceptRecurse(c, depth-1)
+}
+
+func TestACaller_CallerOK_deepStack(t *testing.T) {
+	const expectedName = "github.com/gdey/caller_test.TestACaller_CallerOK_deepStack"
+	var c caller.ACaller
+	c.IgnoreFunction("deepRecurse")
+	// the ignored frames are many chunks deeper then the number of frames to get
+	for _, depth := range []int{0, caller.DefaultNumberOfFramesToGet, caller.DefaultNumberOfFramesToGet * 20} {
+		frame, ok := deepRecurse(c, depth)
+		if !ok {
+			t.Fatalf("ok for depth %v, expected true got false", depth)
+		}
+		if frame.Function != expectedName {
+			t.Errorf("frame for depth %v expected '%v' got '%v'", depth, expectedName, frame.Function)
+		}
+	}
+
+	// the searches for a frame walk the whole stack as well
+	if frame, ok := deepExceptRecurse(c, caller.DefaultNumberOfFramesToGet*20); !ok || frame.Function != expectedName {
+		t.Errorf("except frame expected '%v', true got '%v', %v", expectedName, frame.Function, ok)
+	}
+
+	c.IgnorePackage()
+	c.IgnorePackageNamed("testing")
+	if frame, ok := deepRecurse(c, caller.DefaultNumberOfFramesToGet*3); ok {
+		t.Errorf("all ignored ok, expected false got true, frame %v", frame.Function)
+	}
+}
+
+func callerErrOf(c caller.ACaller) (runtime.Frame, error) { return c.CallerErr() }
+
+func callerErrRecurse(c caller.ACaller, depth int) (runtime.Frame, error) {
+	if depth == 0 {
+		return callerErrOf(c)
+	}
+	return callerErrRecurse(c, depth-1)
+}
+
+func TestACaller_CallerErr(t *testing.T) {
+	t.Run("found", func(t *testing.T) {
+		const expectedName = "github.com/gdey/caller_test.TestACaller_CallerErr.func1"
+		var c caller.ACaller
+		frame, err := callerErrOf(c)
+		if err != nil {
+			t.Fatalf("error, expected nil got %v", err)
+		}
+		if frame.Function != expectedName {
+			t.Errorf("frame expected '%v' got '%v'", expectedName, frame.Function)
+		}
+	})
+	t.Run("no callers", func(t *testing.T) {
+		var c caller.ACaller
+		c.IgnorePackage()
+		done := make(chan error)
+		go func() {
+			_, err := callerErrOf(c)
+			done <- err
+		}()
+		if err := <-done; err != caller.ErrNoCallers {
+			t.Errorf("error, expected %v got %v", caller.ErrNoCallers, err)
+		}
+	})
+	t.Run("deep stack", func(t *testing.T) {
+		const expectedName = "github.com/gdey/caller_test.TestACaller_CallerErr.func3"
+		var c caller.ACaller
+		c.IgnoreFunction("callerErrRecurse")
+		frame, err := callerErrRecurse(c, caller.DefaultNumberOfFramesToGet*5)
+		if err != nil {
+			t.Fatalf("error, expected nil got %v", err)
+		}
+		if frame.Function != expectedName {
+			t.Errorf("frame expected '%v' got '%v'", expectedName, frame.Function)
+		}
+	})
+}
+
+func atFileLineOf(c caller.ACaller, file string, line int) (runtime.Frame, bool) {
+	return c.CallerAtFileLine(file, line)
+}
+
+func TestACaller_CallerAtFileLine(t *testing.T) {
+	const expectedName = "github.com/gdey/caller_test.TestACaller_CallerAtFileLine"
+	var c caller.ACaller
+	_, file, line, _ := runtime.Caller(0)
+	line += 3 // the line calling atFileLineOf
+	for _, name := range []string{file, path.Join(path.Base(path.Dir(file)), path.Base(file)), path.Base(file)} {
+		frame, ok := atFileLineOf(c, name, line)
+		if !ok {
+			t.Errorf("%v:%v ok, expected true got false", name, line)
+			continue
+		}
+		if frame.Function != expectedName {
+			t.Errorf("%v:%v frame expected '%v' got '%v'", name, line, expectedName, frame.Function)
+		}
+	}
+	if frame, ok := atFileLineOf(c, "bogus.go", line); ok {
+		t.Errorf("bogus ok, expected false got true: %v", frame.Function)
+	}
+	if frame, ok := atFileLineOf(c, file, line+1000); ok {
+		t.Errorf("bogus line ok, expected false got true: %v", frame.Function)
+	}
+}
+
+func TestACaller_IgnoreReceiverMethods(t *testing.T) {
+	const pkg = "github.com/gdey/caller_test."
+	var c caller.ACaller
+	c.IgnoreReceiverMethods("Log", "Info", "log")
+	tests := map[string]bool{
+		pkg + "Log.Info":         true,
+		pkg + "(*Log).Info":      true,
+		pkg + "Log.log":          true,
+		pkg + "(*Log).log":       true,
+		pkg + "Log.Warn":         false,
+		pkg + "(*Log).Warn":      false,
+		pkg + "Other.Info":       false,
+		"example.com/x.Log.Info": false,
+	}
+	for name, expected := range tests {
+		if got := c.IsIgnored(runtime.Frame{Function: name}); got != expected {
+			t.Errorf("ignored %v, expected %v got %v", name, expected, got)
+		}
+	}
+}
+
+func callerFinalOf(c caller.ACaller) (runtime.Frame, bool, bool) { return c.CallerFinal() }
+
+func TestACaller_CallerFinal(t *testing.T) {
+	var c caller.ACaller
+	t.Run("more stack", func(t *testing.T) {
+		const expectedName = "github.com/gdey/caller_test.TestACaller_CallerFinal.func1"
+		frame, isLast, ok := callerFinalOf(c)
+		if !ok {
+			t.Fatalf("ok, expected true got false")
+		}
+		if isLast {
+			t.Errorf("is last, expected false got true")
+		}
+		if frame.Function != expectedName {
+			t.Errorf("frame expected '%v' got '%v'", expectedName, frame.Function)
+		}
+	})
+	t.Run("last", func(t *testing.T) {
+		const expectedName = "github.com/gdey/caller_test.TestACaller_CallerFinal.func2.1"
+		type result struct {
+			frame      runtime.Frame
+			isLast, ok bool
+		}
+		done := make(chan result)
+		go func() {
+			var r result
+			r.frame, r.isLast, r.ok = callerFinalOf(c)
+			done <- r
+		}()
+		r := <-done
+		if !r.ok {
+			t.Fatalf("ok, expected true got false")
+		}
+		if !r.isLast {
+			t.Errorf("is last, expected true got false")
+		}
+		if r.frame.Function != expectedName {
+			t.Errorf("frame expected '%v' got '%v'", expectedName, r.frame.Function)
+		}
+	})
+}
+
+func preferTestHelper(c caller.ACaller) runtime.Frame      { return preferTestInnerHelper(c) }
+func preferTestInnerHelper(c caller.ACaller) runtime.Frame { return callerOf(c) }
+
+func TestACaller_SetPreferTestFunction(t *testing.T) {
+	const expectedName = "github.com/gdey/caller_test.TestACaller_SetPreferTestFunction"
+	var c caller.ACaller
+	if frame := preferTestHelper(c); frame.Function == expectedName {
+		t.Errorf("frame expected the helper got '%v'", frame.Function)
+	}
+	c.SetPreferTestFunction(true)
+	if frame := preferTestHelper(c); frame.Function != expectedName {
+		t.Errorf("frame expected '%v' got '%v'", expectedName, frame.Function)
+	}
+}
+
+func TestACaller_ResetFrames(t *testing.T) {
+	var c caller.ACaller
+	c.IgnorePackageNamed("example.com/app")
+	c.SetNumberOfFramesToGet(caller.DefaultNumberOfFramesToGet * 2)
+	if n := c.NumberOfFramesToGet(); n != caller.DefaultNumberOfFramesToGet*2 {
+		t.Fatalf("frames expected %v got %v", caller.DefaultNumberOfFramesToGet*2, n)
+	}
+	c.ResetFrames()
+	if n := c.NumberOfFramesToGet(); n != caller.DefaultNumberOfFramesToGet {
+		t.Errorf("frames expected %v got %v", caller.DefaultNumberOfFramesToGet, n)
+	}
+	if !c.IsIgnored(runtime.Frame{Function: "example.com/app.main"}) {
+		t.Errorf("ignored, expected the ignore list to survive the reset")
+	}
+}
+
+func TestACaller_Reset(t *testing.T) {
+	var c caller.ACaller
+	c.IgnorePackageNamed("example.com/app")
+	c.IgnorePackagePrefix("example.com/lib/")
+	c.IgnoreFunctionReason("example.com/other.Log", "logger")
+	c.IgnoreFileExtensions(".pb.go")
+	c.AddInfoFilter(func(caller.CallerInfo) bool { return true })
+	c.SetNumberOfFramesToGet(caller.DefaultNumberOfFramesToGet * 2)
+	c.Reset()
+	if packages, functions := c.IgnoreCounts(); packages != 0 || functions != 0 {
+		t.Errorf("counts expected 0, 0 got %v, %v", packages, functions)
+	}
+	for _, name := range []string{"example.com/app.main", "example.com/lib/log.Info", "example.com/other.Log"} {
+		if c.IsIgnored(runtime.Frame{Function: name}) {
+			t.Errorf("%v ignored, expected false got true", name)
+		}
+	}
+	if frame := (runtime.Frame{Function: "example.com/api.Get", File: "/src/api/api.pb.go"}); c.IsIgnored(frame) {
+		t.Errorf("%v ignored, expected false got true", frame.File)
+	}
+	if reasons := c.IgnoreReasons(); len(reasons) != 0 {
+		t.Errorf("reasons expected none got %v", reasons)
+	}
+	if n := c.NumberOfFramesToGet(); n != caller.DefaultNumberOfFramesToGet {
+		t.Errorf("frames expected %v got %v", caller.DefaultNumberOfFramesToGet, n)
+	}
+}
+
+func TestReset(t *testing.T) {
+	defer caller.Reset()
+	caller.IgnorePackage()
+	caller.SetNumberOfFramesToGet(caller.DefaultNumberOfFramesToGet * 2)
+	caller.Reset()
+	if packages, functions := caller.IgnoreCounts(); packages != 0 || functions != 0 {
+		t.Errorf("counts expected 0, 0 got %v, %v", packages, functions)
+	}
+	if n := caller.NumberOfFramesToGet(); n != caller.DefaultNumberOfFramesToGet {
+		t.Errorf("frames expected %v got %v", caller.DefaultNumberOfFramesToGet, n)
+	}
+}
+
+func TestACaller_IgnoreFileExtensions(t *testing.T) {
+	var c caller.ACaller
+	c.IgnoreFileExtensions(".pb.go", "pb.gw.go")
+	tests := map[string]bool{
+		"/src/api/service.pb.go":    true,
+		"/src/api/service.pb.gw.go": true,
+		"/src/api/service.go":       false,
+		"/src/api/servicepb.go":     false,
+	}
+	for file, expected := range tests {
+		frame := runtime.Frame{Function: "example.com/api.(*Service).Get", File: file}
+		if got := c.IsIgnored(frame); got != expected {
+			t.Errorf("ignored %v, expected %v got %v", file, expected, got)
+		}
+	}
+
+	// copies of the caller do not see each others extensions
+	c.IgnoreFileExtensions(".gen.go")
+	one, two := c, c
+	one.IgnoreFileExtensions(".one.go")
+	two.IgnoreFileExtensions(".two.go")
+	for _, tc := range []struct {
+		c    caller.ACaller
+		file string
+	}{{one, "/src/api/api.two.go"}, {two, "/src/api/api.one.go"}} {
+		if frame := (runtime.Frame{Function: "example.com/api.Get", File: tc.file}); tc.c.IsIgnored(frame) {
+			t.Errorf("copy ignored %v, expected false got true", tc.file)
+		}
+	}
+}
+
+func capturePCsOf(c caller.ACaller) []uintptr { return c.CapturePCs() }
+
+func capturePCsHelper(c caller.ACaller) []uintptr { return capturePCsOf(c) }
+
+func TestACaller_CapturePCs(t *testing.T) {
+	var c caller.ACaller
+	// the ignore lists are not applied
+	c.IgnoreFunction("capturePCsHelper")
+	pcs := capturePCsHelper(c)
+	if len(pcs) == 0 {
+		t.Fatalf("pcs, expected some got none")
+	}
+	expected := []string{
+		"github.com/gdey/caller_test.capturePCsHelper",
+		"github.com/gdey/caller_test.TestACaller_CapturePCs",
+		"testing.tRunner",
+	}
+	frames := runtime.CallersFrames(pcs)
+	for i, name := range expected {
+		frame, more := frames.Next()
+		if frame.Function != name {
+			t.Errorf("frame %v expected '%v' got '%v'", i, name, frame.Function)
+		}
+		if !more {
+			t.Fatalf("frame %v more, expected true got false", i)
+		}
+	}
+}
+
+func TestACaller_IgnorePackageNamed_dedup(t *testing.T) {
+	var c caller.ACaller
+	for i := 0; i < 3; i++ {
+		c.IgnorePackageNamed("x")
+	}
+	c.IgnorePackage()
+	c.IgnorePackage()
+	expected := []string{"x", "github.com/gdey/caller_test"}
+	if got := c.IgnoredPackages(); !reflect.DeepEqual(got, expected) {
+		t.Errorf("ignored packages expected %v got %v", expected, got)
+	}
+}
+
+func TestACaller_IgnoreCounts(t *testing.T) {
+	var c caller.ACaller
+	c.IgnorePackageNamed("example.com/one")
+	c.IgnorePackageNamed("example.com/two")
+	c.IgnorePackageNamed("example.com/two")
+	c.IgnoreFunctionReason("example.com/app.one", "test")
+	c.IgnoreFunctionReason("example.com/app.two", "test")
+	c.IgnoreFunctionReason("example.com/app.three", "test")
+	if packages, functions := c.IgnoreCounts(); packages != 2 || functions != 3 {
+		t.Errorf("counts expected 2, 3 got %v, %v", packages, functions)
+	}
+	if !c.UnignoreFunction("example.com/app.two") {
+		t.Errorf("removed, expected true got false")
+	}
+	if c.UnignorePackage("example.com/three") {
+		t.Errorf("removed not ignored package, expected false got true")
+	}
+	if packages, functions := c.IgnoreCounts(); packages != 2 || functions != 2 {
+		t.Errorf("counts after removal expected 2, 2 got %v, %v", packages, functions)
+	}
+	if c.IsIgnored(runtime.Frame{Function: "example.com/app.two"}) {
+		t.Errorf("removed function ignored, expected false got true")
+	}
+}
+
+func unignoreCallerOf(c *caller.ACaller) runtime.Frame { return c.Caller() }
+func unignoreHelper(c *caller.ACaller) runtime.Frame   { return unignoreCallerOf(c) }
+
+func TestACaller_Unignore(t *testing.T) {
+	const (
+		testName   = "github.com/gdey/caller_test.TestACaller_Unignore"
+		helperName = "github.com/gdey/caller_test.unignoreHelper"
+	)
+	var c caller.ACaller
+	c.IgnoreFunction("unignoreHelper")
+	if frame := unignoreHelper(&c); frame.Function != testName {
+		t.Errorf("ignored frame expected '%v' got '%v'", testName, frame.Function)
+	}
+	if !c.UnignoreFunction("unignoreHelper") {
+		t.Errorf("unignore function, expected true got false")
+	}
+	if c.UnignoreFunction("unignoreHelper") {
+		t.Errorf("unignore function twice, expected false got true")
+	}
+	if frame := unignoreHelper(&c); frame.Function != helperName {
+		t.Errorf("unignored frame expected '%v' got '%v'", helperName, frame.Function)
+	}
+
+	// fully qualified names, of any package, can be removed
+	qualified := caller.New(caller.WithIgnoredFunctions(helperName, "example.com/log.Info", "fmt.Println"))
+	for _, name := range []string{helperName, "example.com/log.Info", "fmt.Println"} {
+		if !qualified.UnignoreFunction(name) {
+			t.Errorf("unignore %v, expected true got false", name)
+		}
+	}
+	if _, functions := qualified.IgnoreCounts(); functions != 0 {
+		t.Errorf("functions after unignore, expected 0 got %v", qualified.IgnoredFunctions())
+	}
+
+	c.IgnorePackage()
+	c.IgnorePackagePrefix("example.com/")
+	if !c.UnignorePackage("github.com/gdey/caller_test") {
+		t.Errorf("unignore package, expected true got false")
+	}
+	if !c.UnignorePackage("example.com/") {
+		t.Errorf("unignore prefix, expected true got false")
+	}
+	if c.UnignorePackage("example.com/other") {
+		t.Errorf("unignore not ignored package, expected false got true")
+	}
+	if c.IsIgnored(runtime.Frame{Function: "example.com/app.Func"}) {
+		t.Errorf("unignored prefix ignored, expected false got true")
+	}
+	if frame := unignoreHelper(&c); frame.Function != helperName {
+		t.Errorf("unignored package frame expected '%v' got '%v'", helperName, frame.Function)
+	}
+}
+
+func firstAfterStdlibOf(c caller.ACaller) (runtime.Frame, bool) { return c.FirstAfterStdlib() }
+
+func firstAfterStdlibHelper(c caller.ACaller) (runtime.Frame, bool) { return firstAfterStdlibOf(c) }
+
+func TestACaller_FirstAfterStdlib(t *testing.T) {
+	const expectedName = "github.com/gdey/caller_test.TestACaller_FirstAfterStdlib.func1"
+	var (
+		c     caller.ACaller
+		frame runtime.Frame
+		ok    bool
+	)
+	// the less function is the user code called by the standard library
+	values := []int{2, 1}
+	sort.Slice(values, func(i, j int) bool {
+		frame, ok = firstAfterStdlibHelper(c)
+		return values[i] < values[j]
+	})
+	if !ok {
+		t.Fatalf("ok, expected true got false")
+	}
+	if frame.Function != expectedName {
+		t.Errorf("frame expected '%v' got '%v'", expectedName, frame.Function)
+	}
+}
+
+// stdLogOutput is like log.Output; it reports the caller at calldepth
+func stdLogOutput(c caller.ACaller, calldepth int) (runtime.Frame, bool) {
+	return c.CallerLikeStdLog(calldepth)
+}
+
+// stdLogWrapper is like log.Print; it calls stdLogOutput with the depth of it's caller
+func stdLogWrapper(c caller.ACaller) (runtime.Frame, bool) { return stdLogOutput(c, 2) }
+
+func TestACaller_CallerLikeStdLog(t *testing.T) {
+	var c caller.ACaller
+	t.Run("runtime.Caller", func(t *testing.T) {
+		for depth := 0; depth < 2; depth++ {
+			pc, file, line, _ := runtime.Caller(depth)
+			frame, ok := c.CallerLikeStdLog(depth)
+			if depth == 0 {
+				line++
+			}
+			if !ok {
+				t.Fatalf("depth %v ok, expected true got false", depth)
+			}
+			if name := runtime.FuncForPC(pc).Name(); frame.Function != name {
+				t.Errorf("depth %v function expected '%v' got '%v'", depth, name, frame.Function)
+			}
+			if frame.File != file || frame.Line != line {
+				t.Errorf("depth %v location expected %v:%v got %v:%v", depth, file, line, frame.File, frame.Line)
+			}
+		}
+	})
+	t.Run("ignored", func(t *testing.T) {
+		const expectedName = "github.com/gdey/caller_test.TestACaller_CallerLikeStdLog.func2"
+		frame, _ := stdLogWrapper(c)
+		if frame.Function != expectedName {
+			t.Errorf("frame expected '%v' got '%v'", expectedName, frame.Function)
+		}
+		c := c
+		c.IgnoreFunction("TestACaller_CallerLikeStdLog.func2")
+		frame, _ = stdLogWrapper(c)
+		if frame.Function != "testing.tRunner" {
+			t.Errorf("ignored frame expected 'testing.tRunner' got '%v'", frame.Function)
+		}
+	})
+}
+
+func framesSufficientOf(c caller.ACaller) (int, bool) { return c.FramesSufficient() }
+
+func framesSufficientRecurse(c caller.ACaller, depth int) (int, bool) {
+	if depth == 0 {
+		return framesSufficientOf(c)
+	}
+	return framesSufficientRecurse(c, depth-1)
+}
+
+func TestACaller_FramesSufficient(t *testing.T) {
+	var c caller.ACaller
+	if needed, ok := framesSufficientOf(c); !ok || needed != 1 {
+		t.Errorf("shallow, expected 1, true got %v, %v", needed, ok)
+	}
+
+	const depth = caller.DefaultNumberOfFramesToGet * 2
+	c.IgnoreFunction("framesSufficientRecurse")
+	needed, ok := framesSufficientRecurse(c, depth)
+	if ok {
+		t.Errorf("deep ok, expected false got true")
+	}
+	// the frames of framesSufficientRecurse and the test
+	if needed != depth+2 {
+		t.Errorf("deep needed, expected %v got %v", depth+2, needed)
+	}
+	c.SetNumberOfFramesToGet(uint(needed))
+	if _, ok := framesSufficientRecurse(c, depth); !ok {
+		t.Errorf("deep with enough frames ok, expected true got false")
+	}
+}
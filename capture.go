@@ -0,0 +1,68 @@
+package caller
+
+// This file contains the lazily resolved form of the stack, for use on hot paths.
+
+import (
+	"fmt"
+	"io"
+	"runtime"
+)
+
+// Stack is a captured stack, see Capture. Only the program counters are stored when the stack is captured; they are
+// resolved to frames, and the ignore lists applied, when the stack is used. The zero value is an empty stack.
+type Stack struct {
+	c   ACaller
+	pcs []uintptr
+}
+
+// Capture captures the stack, starting with the function that called the function calling Capture; the same frame
+// Caller would start with. Only the program counters are stored, which is much cheaper then resolving the frames;
+// so the stack can be captured on every log call, and only resolved if it is formatted. The ignore lists, at the
+// time of the capture, are applied when the stack is resolved.
+func (c ACaller) Capture() Stack {
+	return Stack{c: c, pcs: c.stackPCs(5)}
+}
+
+// Frames resolves the captured stack, returning the frames that are not in the ignore lists; the same frames
+// Callers would have returned at the time of the capture.
+func (s Stack) Frames() (filtered []runtime.Frame) {
+	if len(s.pcs) == 0 {
+		return nil
+	}
+	var (
+		frames = runtime.CallersFrames(s.pcs)
+		frame  runtime.Frame
+		more   = true
+	)
+	for more {
+		frame, more = frames.Next()
+		if s.c.skipFrame(frame) {
+			continue
+		}
+		filtered = append(filtered, frame)
+	}
+	return filtered
+}
+
+// Trace resolves the captured stack into a Trace.
+func (s Stack) Trace() Trace { return Trace{Frames: s.Frames()} }
+
+// String resolves the captured stack, in the form written by FormatStack.
+func (s Stack) String() string { return formatFrames(s.Frames()) }
+
+// Format implements fmt.Formatter, so the stack is only resolved when it is formatted. The verbs %s and %v write
+// the stack in the form written by FormatStack, and %q writes it as a quoted string.
+func (s Stack) Format(f fmt.State, verb rune) {
+	switch verb {
+	case 's', 'v':
+		_, _ = io.WriteString(f, s.String())
+	case 'q':
+		_, _ = fmt.Fprintf(f, "%q", s.String())
+	default:
+		_, _ = fmt.Fprintf(f, "%%!%c(caller.Stack)", verb)
+	}
+}
+
+// Copyright 2021 Gautam Dey. All rights reserved.
+// Use of this source code is governed by the MIT
+// license that can be found in the LICENSE FILE
@@ -0,0 +1,70 @@
+package caller_test
+
+import (
+	"fmt"
+	"reflect"
+	"runtime"
+	"testing"
+
+	"github.com/gdey/caller"
+)
+
+func captureOf(c caller.ACaller) caller.Stack { return c.Capture() }
+
+func TestACaller_Capture(t *testing.T) {
+	const expectedName = "github.com/gdey/caller_test.TestACaller_Capture"
+	var c caller.ACaller
+	c.IgnorePackageNamed("testing")
+	_, file, line, _ := runtime.Caller(0)
+	stack := func() caller.Stack { return captureOf(c) }()
+	// the ignore lists at the time of the capture are used
+	c.IgnoreFunction("TestACaller_Capture")
+
+	var names []string
+	for _, frame := range stack.Frames() {
+		names = append(names, frame.Function)
+	}
+	expected := []string{expectedName + ".func1", expectedName}
+	if !reflect.DeepEqual(names, expected) {
+		t.Errorf("frames expected %v got %v", expected, names)
+	}
+
+	formatted := fmt.Sprintf(
+		"%v.func1(...)\n\t%v:%v\n%v(...)\n\t%v:%v\n",
+		expectedName, file, line+1, expectedName, file, line+1,
+	)
+	if got := fmt.Sprintf("%v", stack); got != formatted {
+		t.Errorf("%%v expected:\n%v\ngot:\n%v", formatted, got)
+	}
+	if got := fmt.Sprintf("%q", stack); got != fmt.Sprintf("%q", formatted) {
+		t.Errorf("%%q expected %q got %v", formatted, got)
+	}
+	if got := fmt.Sprintf("%d", stack); got != "%!d(caller.Stack)" {
+		t.Errorf("%%d expected '%%!d(caller.Stack)' got '%v'", got)
+	}
+
+	var empty caller.Stack
+	if frames := empty.Frames(); len(frames) != 0 {
+		t.Errorf("empty stack frames, expected none got %v", frames)
+	}
+}
+
+func captureRecurse(c caller.ACaller, depth int) caller.Stack {
+	if depth == 0 {
+		return captureOf(c)
+	}
+	return captureRecurse(c, depth-1)
+}
+
+func TestACaller_Capture_deepStack(t *testing.T) {
+	const depth = caller.DefaultNumberOfFramesToGet * 4
+	var c caller.ACaller
+	frames := captureRecurse(c, depth).Frames()
+	// the depth+1 frames of captureRecurse, and then the test
+	if len(frames) <= depth+1 {
+		t.Fatalf("frames, expected more then %v got %v", depth+1, len(frames))
+	}
+	if name := frames[depth+1].Function; name != "github.com/gdey/caller_test.TestACaller_Capture_deepStack" {
+		t.Errorf("frame %v expected 'github.com/gdey/caller_test.TestACaller_Capture_deepStack' got '%v'", depth+1, name)
+	}
+}
@@ -0,0 +1,57 @@
+package caller
+
+// This file contains the composition of callers into a fallback chain.
+
+import "runtime"
+
+// Interface is the set of methods, used to find the caller, shared by ACaller and the callers built from it.
+type Interface interface {
+	// Caller returns the caller, or the zero frame if one could not be found
+	Caller() (frame runtime.Frame)
+	// CallerOK returns the caller, and weather one outside of the ignore lists was found
+	CallerOK() (frame runtime.Frame, ok bool)
+}
+
+var (
+	_ Interface = ACaller{}
+	_ Interface = FrozenCaller{}
+	_ Interface = ChainedCaller{}
+)
+
+// ChainedCaller is a fallback chain of callers, see Chain.
+type ChainedCaller struct {
+	callers []*ACaller
+}
+
+// Chain returns a caller that will try each of the callers, in order, until one of them finds a caller. If none of
+// them do, the result of the last caller is used. This allows a strict caller to fallback to a more lenient one.
+func Chain(callers ...*ACaller) ChainedCaller {
+	return ChainedCaller{callers: append([]*ACaller(nil), callers...)}
+}
+
+// caller returns the result of the first caller in the chain to find a caller; skip is passed to getFrames.
+func (ch ChainedCaller) caller(skip int) (frame runtime.Frame, ok bool) {
+	for _, c := range ch.callers {
+		if c == nil {
+			continue
+		}
+		if frame, ok = c.caller(skip + 1); ok {
+			return frame, true
+		}
+	}
+	return frame, false
+}
+
+// Caller is like ACaller.Caller, using the first caller in the chain that finds a caller.
+func (ch ChainedCaller) Caller() (frame runtime.Frame) {
+	frame, _ = ch.caller(5)
+	return frame
+}
+
+// CallerOK is like ACaller.CallerOK, using the first caller in the chain that finds a caller. ok is false if
+// none of the callers found a caller.
+func (ch ChainedCaller) CallerOK() (frame runtime.Frame, ok bool) { return ch.caller(5) }
+
+// Copyright 2021 Gautam Dey. All rights reserved.
+// Use of this source code is governed by the MIT
+// license that can be found in the LICENSE FILE
@@ -0,0 +1,33 @@
+package caller_test
+
+import (
+	"runtime"
+	"testing"
+
+	"github.com/gdey/caller"
+)
+
+func chainOf(ch caller.Interface) (runtime.Frame, bool) { return ch.CallerOK() }
+
+func TestChain(t *testing.T) {
+	const expectedName = "github.com/gdey/caller_test.TestChain"
+	var strict, lenient caller.ACaller
+	strict.IgnorePackageNamed("github.com/gdey/caller_test")
+	strict.IgnorePackageNamed("testing")
+
+	if frame, ok := chainOf(strict); ok {
+		t.Fatalf("strict ok, expected false got true: %v", frame.Function)
+	}
+
+	frame, ok := chainOf(caller.Chain(&strict, &lenient))
+	if !ok {
+		t.Fatalf("ok, expected true got false")
+	}
+	if frame.Function != expectedName {
+		t.Errorf("frame expected '%v' got '%v'", expectedName, frame.Function)
+	}
+
+	if _, ok := chainOf(caller.Chain(&strict)); ok {
+		t.Errorf("only strict ok, expected false got true")
+	}
+}
@@ -0,0 +1,81 @@
+package caller
+
+// This file contains the classification of frames, which is cached by program counter.
+
+import (
+	"runtime"
+	"sync"
+)
+
+// frameClass is the coarse classification of a frame, used to quickly decide the frames that are always skipped.
+type frameClass uint8
+
+const (
+	// classUser is a frame of user code
+	classUser frameClass = iota
+	// classSelf is a frame of this package, or one of it's aliases
+	classSelf
+	// classRuntime is a frame of the runtime package
+	classRuntime
+	// classStdlib is a frame of the standard library, other then the runtime package
+	classStdlib
+)
+
+// classifiedFrame is the classification of the frame at a program counter; the function name is kept to make sure
+// the program counter is of the same frame.
+type classifiedFrame struct {
+	function string
+	class    frameClass
+}
+
+// classCache is the cache of the frame classifications, keyed by program counter
+var classCache = struct {
+	sync.RWMutex
+	classes map[uintptr]classifiedFrame
+}{classes: make(map[uintptr]classifiedFrame)}
+
+// resetClassCache clears the classification cache; it needs to be called when the classification of a package
+// changes, such as when a self alias is added.
+func resetClassCache() {
+	classCache.Lock()
+	classCache.classes = make(map[uintptr]classifiedFrame)
+	classCache.Unlock()
+}
+
+// classifyName returns the classification of the full function name.
+func classifyName(fullFuncName string) frameClass {
+	packageName := PackageName(fullFuncName)
+	switch {
+	case packageName == "runtime":
+		return classRuntime
+	case isSelfPackage(packageName):
+		return classSelf
+	case isStandardLibrary(packageName):
+		return classStdlib
+	default:
+		return classUser
+	}
+}
+
+// classifyFrame returns the classification of the frame, using the cache if the frame has a program counter.
+func classifyFrame(frame runtime.Frame) frameClass {
+	if frame.PC == 0 {
+		// synthetic frames do not have a program counter
+		return classifyName(frame.Function)
+	}
+	classCache.RLock()
+	classified, ok := classCache.classes[frame.PC]
+	classCache.RUnlock()
+	if ok && classified.function == frame.Function {
+		return classified.class
+	}
+	class := classifyName(frame.Function)
+	classCache.Lock()
+	classCache.classes[frame.PC] = classifiedFrame{function: frame.Function, class: class}
+	classCache.Unlock()
+	return class
+}
+
+// Copyright 2021 Gautam Dey. All rights reserved.
+// Use of this source code is governed by the MIT
+// license that can be found in the LICENSE FILE
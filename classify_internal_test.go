@@ -0,0 +1,83 @@
+package caller
+
+import (
+	"runtime"
+	"testing"
+)
+
+// stackFrames returns the frames of the current stack
+func stackFrames() (frames []runtime.Frame) {
+	pc := make([]uintptr, 64)
+	n := runtime.Callers(0, pc)
+	iter := runtime.CallersFrames(pc[:n])
+	for more := true; more; {
+		var frame runtime.Frame
+		frame, more = iter.Next()
+		frames = append(frames, frame)
+	}
+	return frames
+}
+
+func TestClassifyFrame(t *testing.T) {
+	frames := append(stackFrames(),
+		runtime.Frame{Function: "example.com/app.main"},
+		runtime.Frame{Function: "net/http.HandlerFunc.ServeHTTP"},
+		runtime.Frame{Function: ourPackageName + ".ACaller.Caller"},
+	)
+	// twice, so the second time the cached classification is used
+	for i := 0; i < 2; i++ {
+		for _, frame := range frames {
+			if got, expected := classifyFrame(frame), classifyName(frame.Function); got != expected {
+				t.Errorf("classify %v, expected %v got %v", frame.Function, expected, got)
+			}
+		}
+	}
+	expected := map[string]frameClass{
+		"runtime.Callers":               classRuntime,
+		ourPackageName + ".stackFrames": classSelf,
+		"testing.tRunner":               classStdlib,
+		"example.com/app.main":          classUser,
+	}
+	for _, frame := range frames {
+		if class, ok := expected[frame.Function]; ok && classifyFrame(frame) != class {
+			t.Errorf("classify %v, expected %v got %v", frame.Function, class, classifyFrame(frame))
+		}
+	}
+}
+
+func TestClassifyFrame_selfAlias(t *testing.T) {
+	const alias = "example.com/fork/caller"
+	frame := runtime.Frame{Function: alias + ".Caller", PC: 1}
+	if class := classifyFrame(frame); class != classUser {
+		t.Fatalf("classify before alias, expected %v got %v", classUser, class)
+	}
+	aliases, _ := selfAliases.Load().([]string)
+	defer func() {
+		selfAliases.Store(aliases)
+		resetClassCache()
+	}()
+	AddSelfPackageAlias(alias)
+	if class := classifyFrame(frame); class != classSelf {
+		t.Errorf("classify after alias, expected %v got %v", classSelf, class)
+	}
+}
+
+func BenchmarkClassifyName(b *testing.B) {
+	frames := stackFrames()
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		for _, frame := range frames {
+			classifyName(frame.Function)
+		}
+	}
+}
+
+func BenchmarkClassifyFrame(b *testing.B) {
+	frames := stackFrames()
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		for _, frame := range frames {
+			classifyFrame(frame)
+		}
+	}
+}
@@ -0,0 +1,41 @@
+package caller
+
+// This file contains the helpers that use a context to adjust the search for the caller.
+
+import (
+	"context"
+	"runtime"
+)
+
+// skipKey is the context key for the skip offset, see WithSkip
+type skipKey struct{}
+
+// WithSkip returns a copy of ctx that tells CallerCtx to skip n more frames. This allows code that wraps the
+// caller, such as a middleware, to declare the number of frames it adds once. The offset is added to any offset
+// already in the context.
+func WithSkip(ctx context.Context, n int) context.Context {
+	if n <= 0 {
+		return ctx
+	}
+	return context.WithValue(ctx, skipKey{}, Skip(ctx)+n)
+}
+
+// Skip returns the skip offset in the context, see WithSkip.
+func Skip(ctx context.Context) int {
+	if ctx == nil {
+		return 0
+	}
+	n, _ := ctx.Value(skipKey{}).(int)
+	return n
+}
+
+// CallerCtx is like CallerOK, but will skip the number of frames given by the skip offset in the context, see
+// WithSkip, before starting the search for the caller. The skipped frames are skipped even if they are not
+// in the ignore lists.
+func (c ACaller) CallerCtx(ctx context.Context) (frame runtime.Frame, ok bool) {
+	return c.caller(5 + Skip(ctx))
+}
+
+// Copyright 2021 Gautam Dey. All rights reserved.
+// Use of this source code is governed by the MIT
+// license that can be found in the LICENSE FILE
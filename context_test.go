@@ -0,0 +1,59 @@
+package caller_test
+
+import (
+	"context"
+	"runtime"
+	"testing"
+
+	"github.com/gdey/caller"
+)
+
+//go:noinline
+func ctxCallerOf(ctx context.Context, c caller.ACaller) (runtime.Frame, bool) {
+	return c.CallerCtx(ctx)
+}
+
+// ctxMiddleware and ctxHandler are the two frames the middleware adds
+//
+//go:noinline
+func ctxMiddleware(ctx context.Context, c caller.ACaller) (runtime.Frame, bool) {
+	return ctxHandler(ctx, c)
+}
+
+//go:noinline
+func ctxHandler(ctx context.Context, c caller.ACaller) (runtime.Frame, bool) {
+	return ctxCallerOf(ctx, c)
+}
+
+func TestACaller_CallerCtx(t *testing.T) {
+	tests := map[string]struct {
+		ctx          context.Context
+		expectedName string
+	}{
+		"no skip": {
+			ctx:          context.Background(),
+			expectedName: "github.com/gdey/caller_test.ctxHandler",
+		},
+		"skip": {
+			ctx:          caller.WithSkip(context.Background(), 2),
+			expectedName: "github.com/gdey/caller_test.TestACaller_CallerCtx.func1",
+		},
+		"nested skip": {
+			ctx:          caller.WithSkip(caller.WithSkip(context.Background(), 1), 1),
+			expectedName: "github.com/gdey/caller_test.TestACaller_CallerCtx.func1",
+		},
+	}
+	var c caller.ACaller
+	for name, tc := range tests {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			frame, ok := ctxMiddleware(tc.ctx, c)
+			if !ok {
+				t.Fatalf("ok, expected true got false")
+			}
+			if frame.Function != tc.expectedName {
+				t.Errorf("frame expected '%v' got '%v'", tc.expectedName, frame.Function)
+			}
+		})
+	}
+}
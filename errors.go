@@ -0,0 +1,105 @@
+package caller
+
+// This file contains the helpers for annotating errors with the caller.
+
+import (
+	"fmt"
+	"io"
+	"path"
+	"runtime"
+	"strconv"
+)
+
+// ErrorsFrame is like CallerOK, but returns the program counter of the caller in the form used by error
+// packages that record stack frames (such as github.com/pkg/errors); that is the return address, one passed the
+// call instruction. The value can be formatted with ErrorFrame.
+func (c ACaller) ErrorsFrame() (pc uintptr, ok bool) {
+	frame, ok := c.caller(5)
+	if !ok {
+		return 0, false
+	}
+	return frame.PC + 1, true
+}
+
+// ErrorFrame is a program counter, as returned by ErrorsFrame, that formats like the frames of the
+// error packages that record stack frames.
+type ErrorFrame uintptr
+
+// pc returns the program counter of the call instruction
+func (f ErrorFrame) pc() uintptr { return uintptr(f) - 1 }
+
+// location returns the function name, file and line of the frame
+func (f ErrorFrame) location() (function, file string, line int) {
+	fn := runtime.FuncForPC(f.pc())
+	if fn == nil {
+		return "unknown", "unknown", 0
+	}
+	file, line = fn.FileLine(f.pc())
+	return fn.Name(), file, line
+}
+
+// Format formats the frame according to the fmt.Formatter interface.
+//
+//	%s    source file base name
+//	%d    source line
+//	%n    function name, without the package path
+//	%v    equivalent to %s:%d
+//	%+s   function name and the full path of the source file, separated by "\n\t"
+//	%+v   equivalent to %+s:%d
+func (f ErrorFrame) Format(s fmt.State, verb rune) {
+	function, file, line := f.location()
+	switch verb {
+	case 's':
+		if s.Flag('+') {
+			io.WriteString(s, function)
+			io.WriteString(s, "\n\t")
+			io.WriteString(s, file)
+			return
+		}
+		io.WriteString(s, path.Base(file))
+	case 'd':
+		io.WriteString(s, strconv.Itoa(line))
+	case 'n':
+		io.WriteString(s, shortFunctionName(function))
+	case 'v':
+		f.Format(s, 's')
+		io.WriteString(s, ":")
+		f.Format(s, 'd')
+	}
+}
+
+// locationError is an error annotated with the location of the caller that wrapped it, see Wrap.
+type locationError struct {
+	location string
+	msg      string
+	err      error
+}
+
+// Error returns the location, the message, and the wrapped error's message; as location: msg: err
+func (e *locationError) Error() string {
+	if e.msg == "" {
+		return e.location + ": " + e.err.Error()
+	}
+	return e.location + ": " + e.msg + ": " + e.err.Error()
+}
+
+// Unwrap returns the wrapped error
+func (e *locationError) Unwrap() error { return e.err }
+
+// Wrap returns an error that annotates err with the file:line of the caller and msg; the error unwraps to err.
+// If the caller could not be found, the unknown name (see SetUnknownName) is used as the location. If err is nil,
+// nil is returned.
+func (c ACaller) Wrap(err error, msg string) error {
+	if err == nil {
+		return nil
+	}
+	location := c.unknown()
+	if frame, ok := c.caller(5); ok {
+		location = frameFileLine(frame)
+	}
+	return &locationError{location: location, msg: msg, err: err}
+}
+
+// Copyright 2021 Gautam Dey. All rights reserved.
+// Use of this source code is governed by the MIT
+// license that can be found in the LICENSE FILE
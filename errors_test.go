@@ -0,0 +1,62 @@
+package caller_test
+
+import (
+	"errors"
+	"fmt"
+	"path"
+	"runtime"
+	"testing"
+
+	"github.com/gdey/caller"
+)
+
+func errorsFrameOf(c caller.ACaller) (uintptr, bool) { return c.ErrorsFrame() }
+
+func TestACaller_ErrorsFrame(t *testing.T) {
+	const expectedName = "github.com/gdey/caller_test.TestACaller_ErrorsFrame"
+	var c caller.ACaller
+	_, file, line, _ := runtime.Caller(0)
+	pc, ok := errorsFrameOf(c)
+	if !ok {
+		t.Fatalf("ok, expected true got false")
+	}
+	line++
+	frame := caller.ErrorFrame(pc)
+	tests := map[string]string{
+		"%+v": fmt.Sprintf("%v\n\t%v:%v", expectedName, file, line),
+		"%v":  fmt.Sprintf("%v:%v", path.Base(file), line),
+		"%s":  path.Base(file),
+		"%d":  fmt.Sprint(line),
+		"%n":  "TestACaller_ErrorsFrame",
+	}
+	for format, expected := range tests {
+		if got := fmt.Sprintf(format, frame); got != expected {
+			t.Errorf("format %v, expected %q got %q", format, expected, got)
+		}
+	}
+}
+
+func wrapOf(c caller.ACaller, err error, msg string) error { return c.Wrap(err, msg) }
+
+func TestACaller_Wrap(t *testing.T) {
+	var (
+		c           caller.ACaller
+		errOriginal = errors.New("original")
+	)
+	_, file, line, _ := runtime.Caller(0)
+	err := wrapOf(c, errOriginal, "reading config")
+	line++
+	expected := fmt.Sprintf("%v:%v: reading config: original", file, line)
+	if err == nil || err.Error() != expected {
+		t.Fatalf("error expected '%v' got '%v'", expected, err)
+	}
+	if !errors.Is(err, errOriginal) {
+		t.Errorf("errors.Is, expected true got false")
+	}
+	if unwrapped := errors.Unwrap(err); unwrapped != errOriginal {
+		t.Errorf("errors.Unwrap expected '%v' got '%v'", errOriginal, unwrapped)
+	}
+	if err := wrapOf(c, nil, "nothing"); err != nil {
+		t.Errorf("nil error, expected nil got '%v'", err)
+	}
+}
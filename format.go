@@ -0,0 +1,115 @@
+package caller
+
+// This file contains the helpers that describe the caller as a string.
+
+import (
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+)
+
+// DefaultUnknownName is the placeholder used by the string helpers, such as CallerName, when the caller
+// could not be found. This value can be changed via the SetUnknownName function.
+const DefaultUnknownName = "<unknown>"
+
+// unknown returns the configured placeholder for a caller that could not be found
+func (c ACaller) unknown() string {
+	if c.unknownName == "" {
+		return DefaultUnknownName
+	}
+	return c.unknownName
+}
+
+// SetUnknownName will change the placeholder used by the string helpers (CallerName, CallerFileLine,
+// CallerChain, CallerFingerprint, ...) when the caller could not be found. An empty name restores the default.
+func (c *ACaller) SetUnknownName(name string) { c.unknownName = name }
+
+// closureParent returns the name of the named function the closure is declared in, by removing the trailing
+// closure segments (.funcN, and .N for nested closures). Names that are not of a closure are returned as is.
+func closureParent(fullFuncName string) string {
+	pkg := PackageName(fullFuncName)
+	for isClosureName(fullFuncName) {
+		idx := strings.LastIndex(fullFuncName, ".")
+		if idx <= len(pkg) {
+			// what is left is the package; the function is named like a closure
+			break
+		}
+		fullFuncName = fullFuncName[:idx]
+	}
+	return fullFuncName
+}
+
+// SetAttributeClosuresToParent if true will have CallerName and CallerInfo report a caller that is a closure
+// as the named function the closure is declared in; so pkg.Outer.func1 is reported as pkg.Outer.
+func (c *ACaller) SetAttributeClosuresToParent(attribute bool) {
+	c.attributeClosuresToParent = attribute
+}
+
+// functionName returns the name of the frame's function to report, see SetAttributeClosuresToParent.
+func (c ACaller) functionName(frame runtime.Frame) string {
+	if c.attributeClosuresToParent {
+		return closureParent(frame.Function)
+	}
+	return frame.Function
+}
+
+// CallerName returns the full function name (package.Function) of the caller.
+func (c ACaller) CallerName() string {
+	frame, ok := c.caller(5)
+	if !ok {
+		return c.unknown()
+	}
+	return c.functionName(frame)
+}
+
+// CallerFileLine returns the file and line of the caller as file:line.
+func (c ACaller) CallerFileLine() string {
+	frame, ok := c.caller(5)
+	if !ok {
+		return c.unknown()
+	}
+	return frameFileLine(frame)
+}
+
+// CallerChain returns the names, without the package, of the functions on the stack that are not in the ignore
+// lists; from the outermost to the innermost, the caller, separated by " -> ".
+func (c ACaller) CallerChain() string {
+	frames := c.filteredFrames(5)
+	if len(frames) == 0 {
+		return c.unknown()
+	}
+	names := make([]string, 0, len(frames))
+	for i := len(frames) - 1; i >= 0; i-- {
+		names = append(names, shortFunctionName(frames[i].Function))
+	}
+	return strings.Join(names, " -> ")
+}
+
+// CallerFileParts is like CallerOK, but returns the file of the caller split into it's directory, with the
+// trailing separator, and the base name of the file; along with the line number.
+func (c ACaller) CallerFileParts() (dir, base string, line int, ok bool) {
+	frame, ok := c.caller(5)
+	if !ok {
+		return "", "", 0, false
+	}
+	dir, base = filepath.Split(frame.File)
+	return dir, base, frame.Line, true
+}
+
+// CallerShortFile is like CallerOK, but returns only the base name of the caller's file and the line, as
+// printed by loggers such as glog.
+func (c ACaller) CallerShortFile() (file string, line int, ok bool) {
+	frame, ok := c.caller(5)
+	if !ok {
+		return "", 0, false
+	}
+	return filepath.Base(frame.File), frame.Line, true
+}
+
+// frameFileLine formats the frame's location as file:line
+func frameFileLine(frame runtime.Frame) string { return frame.File + ":" + strconv.Itoa(frame.Line) }
+
+// Copyright 2021 Gautam Dey. All rights reserved.
+// Use of this source code is governed by the MIT
+// license that can be found in the LICENSE FILE
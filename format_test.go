@@ -0,0 +1,145 @@
+package caller_test
+
+import (
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"testing"
+
+	"github.com/gdey/caller"
+)
+
+type formatHelpers struct {
+	name, fileLine, chain, fingerprint string
+}
+
+func formatOf(c caller.ACaller) formatHelpers {
+	return formatHelpers{
+		name:        c.CallerName(),
+		fileLine:    c.CallerFileLine(),
+		chain:       c.CallerChain(),
+		fingerprint: c.CallerFingerprint(),
+	}
+}
+
+func TestACaller_CallerName(t *testing.T) {
+	const expectedName = "github.com/gdey/caller_test.TestACaller_CallerName"
+	var c caller.ACaller
+	_, file, line, _ := runtime.Caller(0)
+	helpers := formatOf(c)
+	if helpers.name != expectedName {
+		t.Errorf("name expected '%v' got '%v'", expectedName, helpers.name)
+	}
+	if expected := file + ":" + strconv.Itoa(line+1); helpers.fileLine != expected {
+		t.Errorf("file line expected '%v' got '%v'", expected, helpers.fileLine)
+	}
+	if expected := "tRunner -> TestACaller_CallerName"; helpers.chain != expected {
+		t.Errorf("chain expected '%v' got '%v'", expected, helpers.chain)
+	}
+}
+
+func TestACaller_SetUnknownName(t *testing.T) {
+	tests := map[string]struct {
+		name     string
+		expected string
+	}{
+		"default": {expected: caller.DefaultUnknownName},
+		"custom":  {name: "???", expected: "???"},
+	}
+	for name, tc := range tests {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			var c caller.ACaller
+			c.SetUnknownName(tc.name)
+			// ignore this package; in a new goroutine nothing is left on the stack, so the caller is not found.
+			c.IgnorePackage()
+			done := make(chan formatHelpers)
+			go func() { done <- formatOf(c) }()
+			helpers := <-done
+			if helpers.name != tc.expected {
+				t.Errorf("name expected '%v' got '%v'", tc.expected, helpers.name)
+			}
+			if helpers.fileLine != tc.expected {
+				t.Errorf("file line expected '%v' got '%v'", tc.expected, helpers.fileLine)
+			}
+			if helpers.chain != tc.expected {
+				t.Errorf("chain expected '%v' got '%v'", tc.expected, helpers.chain)
+			}
+			if helpers.fingerprint != tc.expected {
+				t.Errorf("fingerprint expected '%v' got '%v'", tc.expected, helpers.fingerprint)
+			}
+		})
+	}
+}
+
+func filePartsOf(c caller.ACaller) (string, string, int, bool) { return c.CallerFileParts() }
+
+func TestACaller_CallerFileParts(t *testing.T) {
+	var c caller.ACaller
+	_, file, expectedLine, _ := runtime.Caller(0)
+	dir, base, line, ok := filePartsOf(c)
+	if !ok {
+		t.Fatalf("ok, expected true got false")
+	}
+	if base != "format_test.go" {
+		t.Errorf("base expected 'format_test.go' got '%v'", base)
+	}
+	if expected := filepath.Dir(file) + string(filepath.Separator); dir != expected {
+		t.Errorf("dir expected '%v' got '%v'", expected, dir)
+	}
+	if line != expectedLine+1 {
+		t.Errorf("line expected %v got %v", expectedLine+1, line)
+	}
+}
+
+func shortFileOf(c caller.ACaller) (string, int, bool) { return c.CallerShortFile() }
+
+func TestACaller_CallerShortFile(t *testing.T) {
+	var c caller.ACaller
+	_, _, expectedLine, _ := runtime.Caller(0)
+	file, line, ok := shortFileOf(c)
+	if !ok {
+		t.Fatalf("ok, expected true got false")
+	}
+	if file != "format_test.go" {
+		t.Errorf("file expected 'format_test.go' got '%v'", file)
+	}
+	if line != expectedLine+1 {
+		t.Errorf("line expected %v got %v", expectedLine+1, line)
+	}
+}
+
+func TestACaller_SetAttributeClosuresToParent(t *testing.T) {
+	const expectedName = "github.com/gdey/caller_test.TestACaller_SetAttributeClosuresToParent"
+	var c caller.ACaller
+	c.SetAttributeClosuresToParent(true)
+	tests := map[string]func() (string, caller.CallerInfo){
+		"closure": func() (string, caller.CallerInfo) {
+			info, _ := infoOf(c)
+			return formatOf(c).name, info
+		},
+		"nested closure": func() (name string, info caller.CallerInfo) {
+			func() {
+				info, _ = infoOf(c)
+				name = formatOf(c).name
+			}()
+			return name, info
+		},
+	}
+	for name, fn := range tests {
+		t.Run(name, func(t *testing.T) {
+			name, info := fn()
+			if name != expectedName {
+				t.Errorf("name expected '%v' got '%v'", expectedName, name)
+			}
+			if info.Function != "TestACaller_SetAttributeClosuresToParent" {
+				t.Errorf("info function expected 'TestACaller_SetAttributeClosuresToParent' got '%v'", info.Function)
+			}
+		})
+	}
+
+	c.SetAttributeClosuresToParent(false)
+	if name := func() string { return formatOf(c).name }(); name == expectedName {
+		t.Errorf("name expected a closure got '%v'", name)
+	}
+}
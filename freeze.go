@@ -0,0 +1,62 @@
+package caller
+
+// This file contains the immutable form of ACaller.
+
+import "runtime"
+
+// clone returns a copy of the caller that does not share the ignore lists, or the usage counts, with c
+func (c ACaller) clone() ACaller {
+	clone := c
+	clone.usage = c.usage.copy()
+	clone.ignorePackages = append([]string(nil), c.ignorePackages...)
+	clone.ignoreFunctions = append([]string(nil), c.ignoreFunctions...)
+	clone.ignorePackagePrefixes = append([]string(nil), c.ignorePackagePrefixes...)
+	clone.allowedModules = append([]string(nil), c.allowedModules...)
+	clone.ignoreFileExtensions = append([]string(nil), c.ignoreFileExtensions...)
+	clone.infoFilters = append([]func(CallerInfo) bool(nil), c.infoFilters...)
+	if c.packageLevels != nil {
+		clone.packageLevels = make(map[string]string, len(c.packageLevels))
+		for pkg, level := range c.packageLevels {
+			clone.packageLevels[pkg] = level
+		}
+	}
+	if c.ignoreReasons != nil {
+		clone.ignoreReasons = c.IgnoreReasons()
+	}
+	return clone
+}
+
+// IsIgnored returns weather the frame would be skipped when looking for the caller.
+func (c ACaller) IsIgnored(frame runtime.Frame) bool { return c.skipFrame(frame) }
+
+// FrozenCaller is an immutable copy of an ACaller's configuration, see Freeze. It only provides the query
+// methods; as nothing can change it's configuration it is safe to share between goroutines. It finds the caller the
+// same way an ACaller does, so it is not any faster; the frame classification cache is still shared by all callers.
+type FrozenCaller struct {
+	c ACaller
+}
+
+// Freeze returns an immutable copy of the caller's configuration. Changes made to the ACaller after it is frozen
+// are not seen by the FrozenCaller; nor are the unused ignore counts (see SetWarnOnUnusedIgnores) shared, the
+// frozen caller keeps it's own counts, starting with those at the time of the freeze. This allows a caller to be configured once, at start up,
+// and then shared without worrying about it being changed.
+func (c ACaller) Freeze() FrozenCaller { return FrozenCaller{c: c.clone()} }
+
+// Caller is the same as ACaller.Caller, using the configuration at the time the caller was frozen.
+func (f FrozenCaller) Caller() (frame runtime.Frame) {
+	frame, _ = f.c.caller(5)
+	return frame
+}
+
+// CallerOK is the same as ACaller.CallerOK, using the configuration at the time the caller was frozen.
+func (f FrozenCaller) CallerOK() (frame runtime.Frame, ok bool) { return f.c.caller(5) }
+
+// Callers is the same as ACaller.Callers, using the configuration at the time the caller was frozen.
+func (f FrozenCaller) Callers() []runtime.Frame { return f.c.callers(5) }
+
+// IsIgnored is the same as ACaller.IsIgnored, using the configuration at the time the caller was frozen.
+func (f FrozenCaller) IsIgnored(frame runtime.Frame) bool { return f.c.skipFrame(frame) }
+
+// Copyright 2021 Gautam Dey. All rights reserved.
+// Use of this source code is governed by the MIT
+// license that can be found in the LICENSE FILE
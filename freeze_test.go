@@ -0,0 +1,88 @@
+package caller_test
+
+import (
+	"bytes"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/gdey/caller"
+)
+
+func frozenCallerOf(f caller.FrozenCaller) runtime.Frame { return f.Caller() }
+func frozenHelper(f caller.FrozenCaller) runtime.Frame   { return frozenCallerOf(f) }
+
+func TestACaller_Freeze(t *testing.T) {
+	const expectedName = "github.com/gdey/caller_test.TestACaller_Freeze"
+	var c caller.ACaller
+	c.IgnoreFunction("frozenHelper")
+	frozen := c.Freeze()
+	// changes after the freeze should not be seen by the frozen caller
+	c.IgnoreFunction("TestACaller_Freeze")
+	c.SetAllowedModules("example.com/")
+
+	if frame := frozenHelper(frozen); !strings.HasPrefix(frame.Function, expectedName) {
+		t.Errorf("frame expected '%v' got '%v'", expectedName, frame.Function)
+	}
+	helper := runtime.Frame{Function: "github.com/gdey/caller_test.frozenHelper"}
+	test := runtime.Frame{Function: expectedName}
+	if !frozen.IsIgnored(helper) {
+		t.Errorf("ignored %v, expected true got false", helper.Function)
+	}
+	if frozen.IsIgnored(test) {
+		t.Errorf("ignored %v, expected false got true", test.Function)
+	}
+	if !c.IsIgnored(test) {
+		t.Errorf("ignored %v by the unfrozen caller, expected true got false", test.Function)
+	}
+	frames := func() []runtime.Frame { return frozen.Callers() }()
+	if len(frames) == 0 || frames[0].Function != expectedName {
+		t.Errorf("callers expected to start with '%v' got %v", expectedName, frames)
+	}
+}
+
+func TestACaller_Freeze_unusedIgnores(t *testing.T) {
+	var (
+		c   caller.ACaller
+		buf bytes.Buffer
+	)
+	c.IgnoreFunction("frozenHelperTypo")
+	c.SetWarnOnUnusedIgnores(&buf)
+	c.SetUnusedIgnoreThreshold(2)
+	frozen := c.Freeze()
+	// the changes to, and the calls of, the original are not seen by the frozen caller
+	c.SetUnusedIgnoreThreshold(100)
+	callerOf(c)
+	frozenCallerOf(frozen)
+	if buf.Len() != 0 {
+		t.Fatalf("warnings, expected none before the threshold got %v", buf.String())
+	}
+	frozenCallerOf(frozen)
+	if !strings.Contains(buf.String(), `"github.com/gdey/caller_test.frozenHelperTypo"`) {
+		t.Errorf("warnings, expected warning for frozenHelperTypo got %v", buf.String())
+	}
+}
+
+func benchmarkCaller() caller.ACaller {
+	var c caller.ACaller
+	for _, name := range []string{"Info", "Warn", "Error", "Debug", "log", "output"} {
+		c.IgnoreFunction(name)
+	}
+	return c
+}
+
+func BenchmarkACaller_Caller(b *testing.B) {
+	c := benchmarkCaller()
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		callerOf(c)
+	}
+}
+
+func BenchmarkFrozenCaller_Caller(b *testing.B) {
+	frozen := benchmarkCaller().Freeze()
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		frozenCallerOf(frozen)
+	}
+}
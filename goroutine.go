@@ -0,0 +1,37 @@
+package caller
+
+// This file contains the helpers that identify the goroutine of the caller.
+
+import (
+	"bytes"
+	"runtime"
+	"strconv"
+)
+
+// goroutinePrefix is the start of the header of a goroutine's stack, as written by runtime.Stack
+var goroutinePrefix = []byte("goroutine ")
+
+// CurrentGoID returns the id of the current goroutine, as shown in a stack trace. 0 is returned if the id could not
+// be found. The id is only meant to tell goroutines apart, such as checking a captured value is used on the
+// goroutine it was captured on; not for goroutine local storage.
+func CurrentGoID() uint64 {
+	// only the header of the stack is needed; "goroutine 123 [running]:"
+	buf := make([]byte, 64)
+	buf = buf[:runtime.Stack(buf, false)]
+	buf = bytes.TrimPrefix(buf, goroutinePrefix)
+	if idx := bytes.IndexByte(buf, ' '); idx != -1 {
+		buf = buf[:idx]
+	}
+	id, err := strconv.ParseUint(string(buf), 10, 64)
+	if err != nil {
+		return 0
+	}
+	return id
+}
+
+// SameGoroutine returns weather the caller is running on the goroutine with the given id, see CurrentGoID.
+func (c ACaller) SameGoroutine(goID uint64) bool { return goID != 0 && CurrentGoID() == goID }
+
+// Copyright 2021 Gautam Dey. All rights reserved.
+// Use of this source code is governed by the MIT
+// license that can be found in the LICENSE FILE
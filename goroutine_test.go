@@ -0,0 +1,35 @@
+package caller_test
+
+import (
+	"testing"
+
+	"github.com/gdey/caller"
+)
+
+func TestCurrentGoID(t *testing.T) {
+	var c caller.ACaller
+	id := caller.CurrentGoID()
+	if id == 0 {
+		t.Fatalf("id, expected a goroutine id got 0")
+	}
+	if again := caller.CurrentGoID(); again != id {
+		t.Errorf("id, expected %v got %v", id, again)
+	}
+	if !c.SameGoroutine(id) {
+		t.Errorf("same goroutine, expected true got false")
+	}
+
+	type result struct {
+		id   uint64
+		same bool
+	}
+	done := make(chan result)
+	go func() { done <- result{id: caller.CurrentGoID(), same: c.SameGoroutine(id)} }()
+	r := <-done
+	if r.id == 0 || r.id == id {
+		t.Errorf("other goroutine id, expected a different id then %v got %v", id, r.id)
+	}
+	if r.same {
+		t.Errorf("other goroutine same goroutine, expected false got true")
+	}
+}
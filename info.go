@@ -0,0 +1,175 @@
+package caller
+
+// This file contains the parsing of the caller's function name into it's parts.
+
+import (
+	"runtime"
+	"strings"
+	"sync"
+)
+
+// CallerInfo is the parsed form of a frame.
+type CallerInfo struct {
+	// Package is the import path of the package of the function
+	Package string
+	// Receiver is the type name, without the pointer, of the receiver if the function is a method
+	Receiver string
+	// Function is the name of the function, or the method, without the package or receiver. For closures it
+	// includes the closure suffix, such as Func.func1
+	Function string
+	// File is the source file of the frame
+	File string
+	// Line is the line in the source file of the frame
+	Line int
+}
+
+// funcNames are the parts of a full function name
+type funcNames struct {
+	pkg      string
+	receiver string
+	function string
+}
+
+// namesCache is the cache of the parsed function names; the names of recurring functions are only parsed
+// once and the same strings are reused.
+var namesCache = struct {
+	sync.RWMutex
+	names map[string]funcNames
+}{names: make(map[string]funcNames)}
+
+// isClosureSegment returns weather the name segment is a closure segment (funcN)
+func isClosureSegment(segment string) bool {
+	if !strings.HasPrefix(segment, "func") || len(segment) == len("func") {
+		return false
+	}
+	for _, r := range segment[len("func"):] {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// parseFunctionName splits the full function name into the package, receiver and function name.
+func parseFunctionName(fullFuncName string) (names funcNames) {
+	names.pkg = PackageName(fullFuncName)
+	rest := fullFuncName
+	if names.pkg != "" {
+		rest = fullFuncName[len(names.pkg)+1:]
+	}
+	// generic functions and types have their type parameters elided as [...]
+	rest = strings.Replace(rest, "[...]", "", -1)
+	switch {
+	case strings.HasPrefix(rest, "("):
+		// pointer receiver (*T).Method or (T).Method
+		end := strings.Index(rest, ").")
+		if end == -1 {
+			break
+		}
+		names.receiver = strings.TrimPrefix(rest[1:end], "*")
+		rest = rest[end+2:]
+	default:
+		// value receiver T.Method, or a closure Func.func1
+		dot := strings.Index(rest, ".")
+		if dot == -1 {
+			break
+		}
+		next := rest[dot+1:]
+		if idx := strings.Index(next, "."); idx != -1 {
+			next = next[:idx]
+		}
+		if isClosureSegment(next) {
+			break
+		}
+		names.receiver = rest[:dot]
+		rest = rest[dot+1:]
+	}
+	names.function = rest
+	return names
+}
+
+// cachedFunctionNames returns the parsed names for the full function name, parsing and caching them if
+// they have not been seen before.
+func cachedFunctionNames(fullFuncName string) funcNames {
+	namesCache.RLock()
+	names, ok := namesCache.names[fullFuncName]
+	namesCache.RUnlock()
+	if ok {
+		return names
+	}
+	names = parseFunctionName(fullFuncName)
+	namesCache.Lock()
+	namesCache.names[fullFuncName] = names
+	namesCache.Unlock()
+	return names
+}
+
+// Warm will parse, and cache, the given full function names; so the first call from those functions is as cheap
+// as the calls after it. This moves the cost of parsing the names to start up.
+func (c *ACaller) Warm(fullFuncNames ...string) {
+	for _, name := range fullFuncNames {
+		cachedFunctionNames(name)
+	}
+}
+
+// frameInfo returns the parsed form of the frame
+func frameInfo(frame runtime.Frame) CallerInfo {
+	names := cachedFunctionNames(frame.Function)
+	return CallerInfo{
+		Package:  names.pkg,
+		Receiver: names.receiver,
+		Function: names.function,
+		File:     frame.File,
+		Line:     frame.Line,
+	}
+}
+
+// CallerInfo is like CallerOK, but returns the parsed form of the caller's frame. The parsed names of
+// functions are cached, so repeated calls from the same call site do not parse the names again.
+func (c ACaller) CallerInfo() (info CallerInfo, ok bool) {
+	frame, ok := c.caller(5)
+	if !ok {
+		return info, false
+	}
+	frame.Function = c.functionName(frame)
+	return frameInfo(frame), true
+}
+
+// CallerInfos is like Callers, but returns the parsed form of each of the frames; from the caller to the
+// outermost frame.
+func (c ACaller) CallerInfos() []CallerInfo {
+	frames := c.filteredFrames(5)
+	infos := make([]CallerInfo, 0, len(frames))
+	for _, frame := range frames {
+		frame.Function = c.functionName(frame)
+		infos = append(infos, frameInfo(frame))
+	}
+	return infos
+}
+
+// CallerServiceMethod is like CallerInfo, but returns the receiver type of the caller, as the service, and the name of
+// the method; as used by RPC frameworks when logging Service.Method. Pointer receivers are reported without the
+// pointer. ok is false if there is no caller, or the caller is not a method.
+func (c ACaller) CallerServiceMethod() (service, method string, ok bool) {
+	frame, ok := c.caller(5)
+	if !ok {
+		return "", "", false
+	}
+	names := cachedFunctionNames(frame.Function)
+	if names.receiver == "" {
+		return "", "", false
+	}
+	return names.receiver, names.function, true
+}
+
+// AddInfoFilter will add a filter that is given the parsed form of each frame, and returns weather the frame
+// should be skipped. This is easier to use, than matching on the function name, for rules based on the package
+// or the receiver.
+func (c *ACaller) AddInfoFilter(filter func(info CallerInfo) bool) {
+	// the list may be shared with copies of the caller
+	c.infoFilters = append(append([]func(CallerInfo) bool(nil), c.infoFilters...), filter)
+}
+
+// Copyright 2021 Gautam Dey. All rights reserved.
+// Use of this source code is governed by the MIT
+// license that can be found in the LICENSE FILE
@@ -0,0 +1,93 @@
+package caller
+
+import (
+	"runtime"
+	"testing"
+)
+
+func TestParseFunctionName(t *testing.T) {
+	tests := map[string]funcNames{
+		"github.com/gdey/caller_test.TestCaller_Caller": {
+			pkg: "github.com/gdey/caller_test", function: "TestCaller_Caller",
+		},
+		"github.com/gdey/caller_test.TestCaller_Caller.func1.2": {
+			pkg: "github.com/gdey/caller_test", function: "TestCaller_Caller.func1.2",
+		},
+		"example.com/log.Log.Info": {
+			pkg: "example.com/log", receiver: "Log", function: "Info",
+		},
+		"example.com/log.(*Log).Info": {
+			pkg: "example.com/log", receiver: "Log", function: "Info",
+		},
+		"example.com/log.(*Log).Info.func1": {
+			pkg: "example.com/log", receiver: "Log", function: "Info.func1",
+		},
+		"example.com/log.(*Log).Info-fm": {
+			pkg: "example.com/log", receiver: "Log", function: "Info-fm",
+		},
+		"example.com/log.List[...].Len": {
+			pkg: "example.com/log", receiver: "List", function: "Len",
+		},
+		"example.com/log.(*List[...]).Len.func1": {
+			pkg: "example.com/log", receiver: "List", function: "Len.func1",
+		},
+		"runtime.goexit": {
+			pkg: "runtime", function: "goexit",
+		},
+	}
+	for name, expected := range tests {
+		if got := parseFunctionName(name); got != expected {
+			t.Errorf("parse %v, expected %+v got %+v", name, expected, got)
+		}
+	}
+}
+
+func TestCachedFunctionNames(t *testing.T) {
+	const name = "example.com/log.(*Log).Info"
+	first := cachedFunctionNames(name)
+	if first != parseFunctionName(name) {
+		t.Errorf("cached names, expected %+v got %+v", parseFunctionName(name), first)
+	}
+	allocs := testing.AllocsPerRun(100, func() { cachedFunctionNames(name) })
+	if allocs != 0 {
+		t.Errorf("allocations, expected 0 got %v", allocs)
+	}
+}
+
+// the name of a generic method is used for the benchmarks, as it needs an allocation to parse.
+const benchFunctionName = "example.com/log.(*List[...]).Len.func1"
+
+func TestACaller_Warm(t *testing.T) {
+	names := []string{
+		"example.com/warm.(*Log).Info",
+		"example.com/warm.Handler.ServeHTTP.func1",
+	}
+	var c ACaller
+	c.Warm(names...)
+	for _, name := range names {
+		namesCache.RLock()
+		_, ok := namesCache.names[name]
+		namesCache.RUnlock()
+		if !ok {
+			t.Errorf("cached %v, expected true got false", name)
+		}
+		frame := runtime.Frame{Function: name}
+		if allocs := testing.AllocsPerRun(100, func() { frameInfo(frame) }); allocs != 0 {
+			t.Errorf("allocations for %v, expected 0 got %v", name, allocs)
+		}
+	}
+}
+
+func BenchmarkParseFunctionName(b *testing.B) {
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		parseFunctionName(benchFunctionName)
+	}
+}
+
+func BenchmarkCachedFunctionNames(b *testing.B) {
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		cachedFunctionNames(benchFunctionName)
+	}
+}
@@ -0,0 +1,152 @@
+package caller_test
+
+import (
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/gdey/caller"
+)
+
+type infoLog struct{ c caller.ACaller }
+
+func infoOf(c caller.ACaller) (caller.CallerInfo, bool) { return c.CallerInfo() }
+
+func (l *infoLog) Info() (caller.CallerInfo, bool) { return infoOf(l.c) }
+
+func TestACaller_CallerInfo(t *testing.T) {
+	var l infoLog
+	info, ok := l.Info()
+	if !ok {
+		t.Fatalf("ok, expected true got false")
+	}
+	if info.Package != "github.com/gdey/caller_test" {
+		t.Errorf("package expected 'github.com/gdey/caller_test' got '%v'", info.Package)
+	}
+	if info.Receiver != "infoLog" {
+		t.Errorf("receiver expected 'infoLog' got '%v'", info.Receiver)
+	}
+	if info.Function != "Info" {
+		t.Errorf("function expected 'Info' got '%v'", info.Function)
+	}
+	if !strings.HasSuffix(info.File, "info_test.go") || info.Line == 0 {
+		t.Errorf("file expected 'info_test.go' with a line got '%v:%v'", info.File, info.Line)
+	}
+}
+
+func infosOf(c caller.ACaller) []caller.CallerInfo { return c.CallerInfos() }
+
+func (l *infoLog) Infos() []caller.CallerInfo { return infosOf(l.c) }
+
+func TestACaller_CallerInfos(t *testing.T) {
+	var l infoLog
+	l.c.IgnorePackageNamed("testing")
+	infos := func() []caller.CallerInfo { return l.Infos() }()
+	expected := []caller.CallerInfo{
+		{Package: "github.com/gdey/caller_test", Receiver: "infoLog", Function: "Infos"},
+		{Package: "github.com/gdey/caller_test", Function: "TestACaller_CallerInfos.func1"},
+		{Package: "github.com/gdey/caller_test", Function: "TestACaller_CallerInfos"},
+	}
+	if len(infos) != len(expected) {
+		t.Fatalf("infos expected %v got %v", len(expected), infos)
+	}
+	for i := range expected {
+		got := infos[i]
+		if !strings.HasSuffix(got.File, "info_test.go") || got.Line == 0 {
+			t.Errorf("info %v file expected 'info_test.go' with a line got '%v:%v'", i, got.File, got.Line)
+		}
+		got.File, got.Line = "", 0
+		if got != expected[i] {
+			t.Errorf("info %v expected %+v got %+v", i, expected[i], got)
+		}
+	}
+}
+
+type rpcService struct{ c caller.ACaller }
+
+func serviceMethodOf(c caller.ACaller) (string, string, bool) { return c.CallerServiceMethod() }
+
+func (s rpcService) Get() (string, string, bool) { return serviceMethodOf(s.c) }
+
+func (s *rpcService) Put() (string, string, bool) { return serviceMethodOf(s.c) }
+
+func rpcFunction(c caller.ACaller) (string, string, bool) { return serviceMethodOf(c) }
+
+func TestACaller_CallerServiceMethod(t *testing.T) {
+	type tcase struct {
+		call    func() (string, string, bool)
+		service string
+		method  string
+		ok      bool
+	}
+	var s rpcService
+	fn := func(tc tcase) func(*testing.T) {
+		return func(t *testing.T) {
+			service, method, ok := tc.call()
+			if ok != tc.ok {
+				t.Fatalf("ok, expected %v got %v", tc.ok, ok)
+			}
+			if service != tc.service || method != tc.method {
+				t.Errorf("service method, expected '%v.%v' got '%v.%v'", tc.service, tc.method, service, method)
+			}
+		}
+	}
+	tests := map[string]tcase{
+		"value receiver": {
+			call:    s.Get,
+			service: "rpcService",
+			method:  "Get",
+			ok:      true,
+		},
+		"pointer receiver": {
+			call:    s.Put,
+			service: "rpcService",
+			method:  "Put",
+			ok:      true,
+		},
+		"function": {
+			call: func() (string, string, bool) { return rpcFunction(s.c) },
+		},
+	}
+	for name, tc := range tests {
+		t.Run(name, fn(tc))
+	}
+}
+
+func TestACaller_AddInfoFilter(t *testing.T) {
+	var c caller.ACaller
+	c.AddInfoFilter(func(info caller.CallerInfo) bool { return info.Receiver == "Log" })
+	tests := map[string]bool{
+		"example.com/app.(*Log).Info":     true,
+		"example.com/app.Log.Print":       true,
+		"example.com/app.(*Logger).Info":  false,
+		"example.com/app.Log":             false,
+		"example.com/app.(*Server).Serve": false,
+	}
+	for name, expected := range tests {
+		if got := c.IsIgnored(runtime.Frame{Function: name}); got != expected {
+			t.Errorf("ignored %v, expected %v got %v", name, expected, got)
+		}
+	}
+
+	// copies of the caller do not see each others filters
+	c.AddInfoFilter(func(info caller.CallerInfo) bool { return info.Function == "Serve" })
+	c.AddInfoFilter(func(info caller.CallerInfo) bool { return info.Function == "Listen" })
+	one, two := c, c
+	one.AddInfoFilter(func(info caller.CallerInfo) bool { return info.Receiver == "One" })
+	two.AddInfoFilter(func(info caller.CallerInfo) bool { return info.Receiver == "Two" })
+	if name := "example.com/app.(*Two).Info"; one.IsIgnored(runtime.Frame{Function: name}) {
+		t.Errorf("first copy ignored %v, expected false got true", name)
+	}
+	if name := "example.com/app.(*One).Info"; two.IsIgnored(runtime.Frame{Function: name}) {
+		t.Errorf("second copy ignored %v, expected false got true", name)
+	}
+}
+
+func BenchmarkACaller_CallerInfo(b *testing.B) {
+	var l infoLog
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		l.Info()
+	}
+}
@@ -0,0 +1,55 @@
+package caller
+
+// This file contains the log level policy, that suggests a level based on the caller's package.
+
+import (
+	"runtime"
+	"strings"
+)
+
+// SetPackageLevel sets the level suggested by CallerSuggestedLevel for callers in the package (it's import path),
+// or in the packages under it. This allows the frames of dependencies to be logged at a different level than the
+// frames of the application. An empty level removes the package's level.
+func (c *ACaller) SetPackageLevel(pkg, level string) {
+	pkg = c.canonicalName(pkg)
+	// the map may be shared with copies of the caller
+	c.packageLevels = copyStringMap(c.packageLevels)
+	if level == "" {
+		delete(c.packageLevels, pkg)
+		return
+	}
+	c.packageLevels[pkg] = level
+}
+
+// packageLevel returns the level of the package, from the nearest package that has a level set; the package itself,
+// or the closest package above it.
+func (c ACaller) packageLevel(packageName string) (level string, ok bool) {
+	var matched string
+	for pkg, lvl := range c.packageLevels {
+		if packageName != pkg && !strings.HasPrefix(packageName, pkg+"/") {
+			continue
+		}
+		if len(pkg) > len(matched) {
+			matched, level, ok = pkg, lvl, true
+		}
+	}
+	return level, ok
+}
+
+// CallerSuggestedLevel is like CallerOK, but also returns the level set for the caller's package, see SetPackageLevel;
+// or defaultLevel if there is no level set for the package. If the caller could not be found, defaultLevel is
+// returned and ok is false.
+func (c ACaller) CallerSuggestedLevel(defaultLevel string) (frame runtime.Frame, level string, ok bool) {
+	frame, ok = c.caller(5)
+	if !ok {
+		return frame, defaultLevel, false
+	}
+	if level, found := c.packageLevel(c.canonicalName(PackageName(frame.Function))); found {
+		return frame, level, true
+	}
+	return frame, defaultLevel, true
+}
+
+// Copyright 2021 Gautam Dey. All rights reserved.
+// Use of this source code is governed by the MIT
+// license that can be found in the LICENSE FILE
@@ -0,0 +1,67 @@
+package caller_test
+
+import (
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/gdey/caller"
+)
+
+func suggestedLevelOf(c caller.ACaller) (runtime.Frame, string, bool) {
+	return c.CallerSuggestedLevel("info")
+}
+
+func TestACaller_CallerSuggestedLevel(t *testing.T) {
+	var c caller.ACaller
+	c.SetPackageLevel("github.com/gdey/caller_test", "debug")
+	c.SetPackageLevel("testing", "warn")
+
+	_, level, ok := suggestedLevelOf(c)
+	if !ok {
+		t.Fatalf("ok, expected true got false")
+	}
+	if level != "debug" {
+		t.Errorf("level expected 'debug' got '%v'", level)
+	}
+
+	c.IgnorePackage()
+	frame, level, ok := suggestedLevelOf(c)
+	if !ok {
+		t.Fatalf("testing ok, expected true got false")
+	}
+	if frame.Function != "testing.tRunner" || level != "warn" {
+		t.Errorf("testing level expected 'testing.tRunner' at 'warn' got '%v' at '%v'", frame.Function, level)
+	}
+
+	var other caller.ACaller
+	if _, level, _ := suggestedLevelOf(other); level != "info" {
+		t.Errorf("default level expected 'info' got '%v'", level)
+	}
+	// the closest package above the caller's package is used
+	other.SetPackageLevel("github.com/gdey", "warn")
+	other.SetPackageLevel("github.com/gdey/caller", "error")
+	if _, level, _ := suggestedLevelOf(other); level != "warn" {
+		t.Errorf("parent level expected 'warn' got '%v'", level)
+	}
+
+	// setting a level on a copy does not change the original
+	copied := other
+	copied.SetPackageLevel("github.com/gdey/caller_test", "error")
+	if _, level, _ := suggestedLevelOf(other); level != "warn" {
+		t.Errorf("original level after copy change expected 'warn' got '%v'", level)
+	}
+	if _, level, _ := suggestedLevelOf(copied); level != "error" {
+		t.Errorf("copy level expected 'error' got '%v'", level)
+	}
+}
+
+func TestACaller_SetPackageLevel_canonicalizer(t *testing.T) {
+	var c caller.ACaller
+	c.SetPackageLevel("GitHub.com/GDey/Caller_Test", "debug")
+	// the level set before the canonicalizer still matches
+	c.SetNameCanonicalizer(strings.ToLower)
+	if _, level, _ := suggestedLevelOf(c); level != "debug" {
+		t.Errorf("level expected 'debug' got '%v'", level)
+	}
+}
@@ -0,0 +1,237 @@
+package caller
+
+// This file contains the helpers that use the build information to describe where a caller lives.
+
+import (
+	"path"
+	"runtime"
+	"runtime/debug"
+	"strconv"
+	"strings"
+	"sync"
+	"unicode"
+	"unicode/utf8"
+)
+
+// escapeModulePath escapes the module path the way the module cache does; upper case letters are replaced
+// by an '!' followed by the lower case letter.
+func escapeModulePath(path string) string {
+	var str strings.Builder
+	for _, r := range path {
+		if unicode.IsUpper(r) {
+			str.WriteByte('!')
+			r = unicode.ToLower(r)
+		}
+		str.WriteRune(r)
+	}
+	return str.String()
+}
+
+// buildDeps returns the dependencies of the running binary, if the build information is available.
+func buildDeps() []*debug.Module {
+	info, ok := debug.ReadBuildInfo()
+	if !ok {
+		return nil
+	}
+	return info.Deps
+}
+
+// buildModules returns the main module and the dependencies of the running binary, if the build information is
+// available.
+func buildModules() []*debug.Module {
+	info, ok := debug.ReadBuildInfo()
+	if !ok {
+		return nil
+	}
+	return append([]*debug.Module{&info.Main}, info.Deps...)
+}
+
+// moduleOf returns the path of the module, of the given modules, the package belongs to; this is the module with
+// the longest path that is a prefix of the package.
+func moduleOf(packageName string, modules []*debug.Module) (module string, ok bool) {
+	// packages instrumented by go test have the form "pkg [pkg.test]"
+	if idx := strings.Index(packageName, " ["); idx != -1 {
+		packageName = packageName[:idx]
+	}
+	// external test packages belong to the same module as the package they test
+	packageName = strings.TrimSuffix(packageName, "_test")
+	for _, mod := range modules {
+		if mod.Path == "" || len(mod.Path) <= len(module) {
+			continue
+		}
+		if packageName == mod.Path || strings.HasPrefix(packageName, mod.Path+"/") {
+			module = mod.Path
+		}
+	}
+	return module, module != ""
+}
+
+// mainModule is the path of the main module, read from the build information once; see mainModulePath
+var mainModule struct {
+	once sync.Once
+	path string
+}
+
+// mainModulePath returns the path of the main module of the running binary, or "" if the build information is
+// not available.
+func mainModulePath() string {
+	mainModule.once.Do(func() {
+		if info, ok := debug.ReadBuildInfo(); ok {
+			mainModule.path = info.Main.Path
+		}
+	})
+	return mainModule.path
+}
+
+// isExportedName returns weather the name starts with an upper case letter
+func isExportedName(name string) bool {
+	r, _ := utf8.DecodeRuneInString(name)
+	return unicode.IsUpper(r)
+}
+
+// isPublicEntry returns weather the frame is of an exported function, or an exported method of an exported type,
+// in the main module. Closures are never exported.
+func isPublicEntry(frame runtime.Frame, module string) bool {
+	info := frameInfo(frame)
+	if strings.Contains(info.Function, ".") || !isExportedName(info.Function) {
+		return false
+	}
+	if info.Receiver != "" && !isExportedName(info.Receiver) {
+		return false
+	}
+	_, ok := moduleOf(info.Package, []*debug.Module{{Path: module}})
+	return ok
+}
+
+// CallerPublicEntry is like CallerOK, but returns the first frame, not in the ignore lists, that is an exported function,
+// or an exported method of an exported type, in the main module. This is the public entry point, of the application,
+// that lead to the call. ok is false if there is no such frame, or the main module is not known.
+func (c ACaller) CallerPublicEntry() (frame runtime.Frame, ok bool) {
+	module := mainModulePath()
+	if module == "" {
+		return frame, false
+	}
+	return c.findFrame(5, func(frame runtime.Frame) bool {
+		return isPublicEntry(frame, module)
+	})
+}
+
+// CallerDocURL is like CallerOK, but returns a link to the documentation of the caller's package. The module of the
+// caller's package is found using the build information, and baseForModule is called with it to get the base of
+// the link; such as https://pkg.go.dev for public modules. The link is the base followed by the import path of the
+// package. ok is false if the caller or it's module could not be found, or baseForModule returns "".
+func (c ACaller) CallerDocURL(baseForModule func(module string) string) (url string, ok bool) {
+	frame, ok := c.caller(5)
+	if !ok {
+		return "", false
+	}
+	packageName := PackageName(frame.Function)
+	module, ok := moduleOf(packageName, buildModules())
+	if !ok {
+		return "", false
+	}
+	base := baseForModule(module)
+	if base == "" {
+		return "", false
+	}
+	if idx := strings.Index(packageName, " ["); idx != -1 {
+		packageName = packageName[:idx]
+	}
+	return strings.TrimSuffix(base, "/") + "/" + strings.TrimSuffix(packageName, "_test"), true
+}
+
+// importFile maps a file in the module cache to the module it belongs to. The importPath is of the form
+// module@version and relFile is the path of the file relative to the root of the module. If the file does not
+// belong to one of the given modules ok is false.
+func importFile(file string, deps []*debug.Module) (importPath, relFile string, ok bool) {
+	for _, dep := range deps {
+		if dep.Replace != nil {
+			// if the module is replaced, the source is where the replacement is
+			dep = dep.Replace
+		}
+		if dep.Version == "" {
+			continue
+		}
+		marker := "/" + escapeModulePath(dep.Path) + "@" + dep.Version + "/"
+		idx := strings.LastIndex(file, marker)
+		if idx == -1 {
+			continue
+		}
+		return dep.Path + "@" + dep.Version, file[idx+len(marker):], true
+	}
+	return "", file, false
+}
+
+// CallerImportFile is like CallerOK, but will describe the file of the caller relative to the module it
+// belongs to. For a frame in a dependency, the file in the module cache is mapped, using the build information,
+// to the import path and version of the dependency (importPath) and the file relative to the root of the
+// module (relFile); so it can be rendered as importPath/relFile. If the mapping fails, importPath is empty and relFile
+// is the raw path of the file. ok is false if the caller could not be found.
+func (c ACaller) CallerImportFile() (importPath, relFile string, line int, ok bool) {
+	frame, ok := c.caller(5)
+	if !ok {
+		return "", "", 0, false
+	}
+	importPath, relFile, _ = importFile(frame.File, buildDeps())
+	return importPath, relFile, frame.Line, true
+}
+
+// sourceLocation returns the module, the version of the module, and the file relative to the root of the module,
+// of the frame. Frames in dependencies are found in the module cache, and frames in the main module are found
+// using their package's path within the module.
+func sourceLocation(frame runtime.Frame, main *debug.Module, deps []*debug.Module) (module, version, relFile string, ok bool) {
+	if importPath, rel, found := importFile(frame.File, deps); found {
+		idx := strings.LastIndex(importPath, "@")
+		return importPath[:idx], importPath[idx+1:], rel, true
+	}
+	if main == nil {
+		return "", "", "", false
+	}
+	packageName := PackageName(frame.Function)
+	if idx := strings.Index(packageName, " ["); idx != -1 {
+		packageName = packageName[:idx]
+	}
+	packageName = strings.TrimSuffix(packageName, "_test")
+	module, ok = moduleOf(packageName, []*debug.Module{main})
+	if !ok {
+		return "", "", "", false
+	}
+	// the directory of the file ends with the package's path within the module
+	dir := path.Dir(frame.File)
+	relDir := strings.TrimPrefix(packageName, module)
+	if !strings.HasSuffix(dir, relDir) {
+		return "", "", "", false
+	}
+	root := dir[:len(dir)-len(relDir)]
+	return module, main.Version, strings.TrimPrefix(frame.File, root+"/"), true
+}
+
+// CallerSourceLink is like CallerOK, but returns a link to the caller's source line, built from template. The
+// template can have the placeholders {module}, {version}, {relfile} and {line}; which are replaced by the module of
+// the caller, it's version, the caller's file relative to the root of the module, and the caller's line. For
+// example, https://{module}/blob/{version}/{relfile}#L{line} for a module hosted on GitHub. The pieces are found
+// using the build information. ok is false if the caller, or it's module, could not be found.
+func (c ACaller) CallerSourceLink(template string) (link string, ok bool) {
+	frame, ok := c.caller(5)
+	if !ok {
+		return "", false
+	}
+	var main *debug.Module
+	if modules := buildModules(); len(modules) != 0 {
+		main = modules[0]
+	}
+	module, version, relFile, ok := sourceLocation(frame, main, buildDeps())
+	if !ok {
+		return "", false
+	}
+	return strings.NewReplacer(
+		"{module}", module,
+		"{version}", version,
+		"{relfile}", relFile,
+		"{line}", strconv.Itoa(frame.Line),
+	).Replace(template), true
+}
+
+// Copyright 2021 Gautam Dey. All rights reserved.
+// Use of this source code is governed by the MIT
+// license that can be found in the LICENSE FILE
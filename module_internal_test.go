@@ -0,0 +1,135 @@
+package caller
+
+import (
+	"runtime"
+	"runtime/debug"
+	"testing"
+)
+
+func TestImportFile(t *testing.T) {
+	deps := []*debug.Module{
+		{Path: "github.com/BurntSushi/toml", Version: "v1.2.0"},
+		{Path: "golang.org/x/mod", Version: "v0.8.0"},
+		{
+			Path:    "example.com/old",
+			Version: "v1.0.0",
+			Replace: &debug.Module{Path: "example.com/new", Version: "v1.1.0"},
+		},
+	}
+	type tcase struct {
+		file       string
+		importPath string
+		relFile    string
+		ok         bool
+	}
+	tests := map[string]tcase{
+		"escaped module": {
+			file:       "/home/gopher/go/pkg/mod/github.com/!burnt!sushi/toml@v1.2.0/decode.go",
+			importPath: "github.com/BurntSushi/toml@v1.2.0",
+			relFile:    "decode.go",
+			ok:         true,
+		},
+		"sub directory": {
+			file:       "/home/gopher/go/pkg/mod/golang.org/x/mod@v0.8.0/module/module.go",
+			importPath: "golang.org/x/mod@v0.8.0",
+			relFile:    "module/module.go",
+			ok:         true,
+		},
+		"replaced module": {
+			file:       "/home/gopher/go/pkg/mod/example.com/new@v1.1.0/new.go",
+			importPath: "example.com/new@v1.1.0",
+			relFile:    "new.go",
+			ok:         true,
+		},
+		"not a dependency": {
+			file:    "/home/gopher/src/app/main.go",
+			relFile: "/home/gopher/src/app/main.go",
+		},
+	}
+	for name, tc := range tests {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			importPath, relFile, ok := importFile(tc.file, deps)
+			if ok != tc.ok {
+				t.Errorf("ok, expected %v got %v", tc.ok, ok)
+			}
+			if importPath != tc.importPath {
+				t.Errorf("import path, expected '%v' got '%v'", tc.importPath, importPath)
+			}
+			if relFile != tc.relFile {
+				t.Errorf("rel file, expected '%v' got '%v'", tc.relFile, relFile)
+			}
+		})
+	}
+}
+
+func TestModuleOf(t *testing.T) {
+	modules := []*debug.Module{
+		{Path: "example.com/app"},
+		{Path: "golang.org/x/mod", Version: "v0.8.0"},
+		{Path: "golang.org/x/mod/sumdb", Version: "v0.1.0"},
+	}
+	tests := map[string]string{
+		"example.com/app":                            "example.com/app",
+		"example.com/app/internal/log":               "example.com/app",
+		"example.com/app_test":                       "example.com/app",
+		"example.com/app/log [example.com/app.test]": "example.com/app",
+		"golang.org/x/mod/module":                    "golang.org/x/mod",
+		"golang.org/x/mod/sumdb/note":                "golang.org/x/mod/sumdb",
+		"example.com/application":                    "",
+		"fmt":                                        "",
+	}
+	for pkg, expected := range tests {
+		module, ok := moduleOf(pkg, modules)
+		if module != expected || ok != (expected != "") {
+			t.Errorf("moduleOf(%q), expected '%v' got '%v' (%v)", pkg, expected, module, ok)
+		}
+	}
+}
+
+func TestSourceLocation(t *testing.T) {
+	main := &debug.Module{Path: "example.com/app", Version: "v1.2.3"}
+	deps := []*debug.Module{{Path: "golang.org/x/mod", Version: "v0.8.0"}}
+	tests := map[string]struct {
+		frame    runtime.Frame
+		module   string
+		version  string
+		relFile  string
+		expected bool
+	}{
+		"dependency": {
+			frame: runtime.Frame{
+				Function: "golang.org/x/mod/module.Check",
+				File:     "/go/pkg/mod/golang.org/x/mod@v0.8.0/module/module.go",
+			},
+			module: "golang.org/x/mod", version: "v0.8.0", relFile: "module/module.go", expected: true,
+		},
+		"main module": {
+			frame: runtime.Frame{
+				Function: "example.com/app/internal/log.Info",
+				File:     "/home/user/src/app/internal/log/log.go",
+			},
+			module: "example.com/app", version: "v1.2.3", relFile: "internal/log/log.go", expected: true,
+		},
+		"main module root": {
+			frame:  runtime.Frame{Function: "example.com/app.main", File: "/home/user/src/app/main.go"},
+			module: "example.com/app", version: "v1.2.3", relFile: "main.go", expected: true,
+		},
+		"unknown module": {
+			frame: runtime.Frame{Function: "example.com/other.Func", File: "/src/other/other.go"},
+		},
+	}
+	for name, tc := range tests {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			module, version, relFile, ok := sourceLocation(tc.frame, main, deps)
+			if ok != tc.expected {
+				t.Fatalf("ok, expected %v got %v", tc.expected, ok)
+			}
+			if module != tc.module || version != tc.version || relFile != tc.relFile {
+				t.Errorf("location expected %v@%v %v got %v@%v %v",
+					tc.module, tc.version, tc.relFile, module, version, relFile)
+			}
+		})
+	}
+}
@@ -0,0 +1,102 @@
+package caller_test
+
+import (
+	"fmt"
+	"runtime"
+	"runtime/debug"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/gdey/caller"
+)
+
+func TestACaller_CallerImportFile(t *testing.T) {
+	var c caller.ACaller
+	importPath, relFile, line, ok := func() (string, string, int, bool) { return c.CallerImportFile() }()
+	if !ok {
+		t.Fatalf("ok, expected true got false")
+	}
+	// this package is not a dependency, so we should get the raw path back.
+	if importPath != "" {
+		t.Errorf("import path, expected '' got '%v'", importPath)
+	}
+	if !strings.HasSuffix(relFile, "module_test.go") {
+		t.Errorf("rel file, expected raw path to module_test.go got '%v'", relFile)
+	}
+	if line == 0 {
+		t.Errorf("line, expected non zero line")
+	}
+}
+
+func TestACaller_CallerDocURL(t *testing.T) {
+	var (
+		c       caller.ACaller
+		modules []string
+	)
+	base := func(module string) string {
+		modules = append(modules, module)
+		return "https://docs.example.com/"
+	}
+	url, ok := func() (string, bool) { return c.CallerDocURL(base) }()
+	if !ok {
+		t.Fatalf("ok, expected true got false")
+	}
+	if expected := []string{"github.com/gdey/caller"}; len(modules) != 1 || modules[0] != expected[0] {
+		t.Errorf("modules expected %v got %v", expected, modules)
+	}
+	if expected := "https://docs.example.com/github.com/gdey/caller"; url != expected {
+		t.Errorf("url expected '%v' got '%v'", expected, url)
+	}
+
+	none := func(string) string { return "" }
+	if url, ok := func() (string, bool) { return c.CallerDocURL(none) }(); ok {
+		t.Errorf("no base ok, expected false got true: %v", url)
+	}
+}
+
+// PublicEntryPoint is the exported function of the chain; it calls into the standard library and unexported helpers.
+func PublicEntryPoint(c caller.ACaller) (frame runtime.Frame, ok bool) {
+	values := []int{2, 1}
+	sort.Slice(values, func(i, j int) bool {
+		frame, ok = publicEntryHelper(c)
+		return values[i] < values[j]
+	})
+	return frame, ok
+}
+
+func publicEntryHelper(c caller.ACaller) (runtime.Frame, bool) { return publicEntryInnerHelper(c) }
+
+func publicEntryInnerHelper(c caller.ACaller) (runtime.Frame, bool) { return publicEntryOf(c) }
+
+func publicEntryOf(c caller.ACaller) (runtime.Frame, bool) { return c.CallerPublicEntry() }
+
+func TestACaller_CallerPublicEntry(t *testing.T) {
+	const expectedName = "github.com/gdey/caller_test.PublicEntryPoint"
+	var c caller.ACaller
+	frame, ok := PublicEntryPoint(c)
+	if !ok {
+		t.Fatalf("ok, expected true got false")
+	}
+	if frame.Function != expectedName {
+		t.Errorf("frame expected '%v' got '%v'", expectedName, frame.Function)
+	}
+}
+
+func TestACaller_CallerSourceLink(t *testing.T) {
+	const template = "https://{module}/blob/{version}/{relfile}#L{line}"
+	var c caller.ACaller
+	info, ok := debug.ReadBuildInfo()
+	if !ok {
+		t.Skip("build information is not available")
+	}
+	_, _, line, _ := runtime.Caller(0)
+	link, ok := func() (string, bool) { return c.CallerSourceLink(template) }()
+	if !ok {
+		t.Fatalf("ok, expected true got false")
+	}
+	expected := fmt.Sprintf("https://github.com/gdey/caller/blob/%v/module_test.go#L%v", info.Main.Version, line+1)
+	if link != expected {
+		t.Errorf("link expected '%v' got '%v'", expected, link)
+	}
+}
@@ -0,0 +1,60 @@
+package caller
+
+// This file contains the functional options used to build an ACaller with New.
+
+// Option configures the ACaller built by New.
+type Option func(*ACaller)
+
+// New returns an ACaller configured with the given options, applied in order. Unlike IgnorePackage and Helper,
+// the options do not depend on where they are called from; so library authors that know their ignore lists up
+// front can build the caller in one place.
+func New(opts ...Option) *ACaller {
+	c := ACaller{growRetries: new(uint64)}
+	for _, opt := range opts {
+		if opt != nil {
+			opt(&c)
+		}
+	}
+	return &c
+}
+
+// WithIgnoredPackages adds the named packages (their import paths) to the package ignore list, see IgnorePackageNamed.
+func WithIgnoredPackages(names ...string) Option {
+	return func(c *ACaller) {
+		c.ownIgnoreLists()
+		for _, name := range names {
+			c.addIgnorePackageNamed(name)
+		}
+	}
+}
+
+// WithIgnoredFunctions adds the fully qualified function names (package.FunctionName) to the function ignore list.
+func WithIgnoredFunctions(fullNames ...string) Option {
+	return func(c *ACaller) {
+		c.ownIgnoreLists()
+		for _, name := range fullNames {
+			c.addIgnoreFunction(c.canonicalName(name))
+		}
+	}
+}
+
+// WithNumberOfFramesToGet sets the number of frames to get from the runtime at a time, see SetNumberOfFramesToGet.
+// This is not a limit on the depth of the search; and sizes that are not larger then DefaultNumberOfFramesToGet
+// are ignored, keeping the default.
+func WithNumberOfFramesToGet(n uint) Option {
+	return func(c *ACaller) { c.SetNumberOfFramesToGet(n) }
+}
+
+// WithCallersDepth limits the number of frames returned by Callers, see SetCallersDepth.
+func WithCallersDepth(depth int) Option {
+	return func(c *ACaller) { c.SetCallersDepth(depth) }
+}
+
+// WithSkipRuntime sets weather the frames of the runtime package are skipped, which they are by default.
+func WithSkipRuntime(skip bool) Option {
+	return func(c *ACaller) { c.SetSkipRuntime(skip) }
+}
+
+// Copyright 2021 Gautam Dey. All rights reserved.
+// Use of this source code is governed by the MIT
+// license that can be found in the LICENSE FILE
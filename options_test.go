@@ -0,0 +1,59 @@
+package caller_test
+
+import (
+	"runtime"
+	"testing"
+
+	"github.com/gdey/caller"
+)
+
+func newCallerOf(c *caller.ACaller) runtime.Frame { return c.Caller() }
+func newHelper(c *caller.ACaller) runtime.Frame   { return newCallerOf(c) }
+
+func TestNew(t *testing.T) {
+	const expectedName = "github.com/gdey/caller_test.TestNew"
+	c := caller.New(
+		caller.WithIgnoredPackages("testing"),
+		caller.WithIgnoredFunctions("github.com/gdey/caller_test.newHelper"),
+		caller.WithNumberOfFramesToGet(20),
+	)
+	if frame := newHelper(c); frame.Function != expectedName {
+		t.Errorf("frame expected '%v' got '%v'", expectedName, frame.Function)
+	}
+	if n := c.NumberOfFramesToGet(); n != 20 {
+		t.Errorf("number of frames expected 20 got %v", n)
+	}
+	packages, functions := c.IgnoreCounts()
+	if packages != 1 || functions != 1 {
+		t.Errorf("ignore counts expected 1, 1 got %v, %v", packages, functions)
+	}
+}
+
+func TestWithNumberOfFramesToGet(t *testing.T) {
+	if n := caller.New(caller.WithNumberOfFramesToGet(40)).NumberOfFramesToGet(); n != 40 {
+		t.Errorf("number of frames expected 40 got %v", n)
+	}
+	// sizes below the default are ignored
+	c := caller.New(caller.WithNumberOfFramesToGet(caller.DefaultNumberOfFramesToGet - 10))
+	if n := c.NumberOfFramesToGet(); n != caller.DefaultNumberOfFramesToGet {
+		t.Errorf("number of frames expected %v got %v", caller.DefaultNumberOfFramesToGet, n)
+	}
+}
+
+func TestWithSkipRuntime(t *testing.T) {
+	const goexit = "runtime.goexit"
+	hasRuntime := func(c *caller.ACaller) bool {
+		for _, frame := range c.Callers() {
+			if frame.Function == goexit {
+				return true
+			}
+		}
+		return false
+	}
+	if hasRuntime(caller.New(caller.WithSkipRuntime(true))) {
+		t.Errorf("runtime frames, expected hidden got shown")
+	}
+	if !hasRuntime(caller.New(caller.WithSkipRuntime(false))) {
+		t.Errorf("runtime frames, expected shown got hidden")
+	}
+}
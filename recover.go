@@ -0,0 +1,45 @@
+package caller
+
+// This file contains the helpers for finding the caller while recovering from a panic.
+
+import "runtime"
+
+// RecoverCaller finds the function that panicked, skipping any frames in the ignore lists. It is meant to be
+// used in a deferred function, passing it the value returned by recover:
+//
+//	defer func() {
+//		if frame, r, ok := c.RecoverCaller(recover()); ok {
+//			log.Printf("%v:%v panicked: %v", frame.File, frame.Line, r)
+//		}
+//	}()
+//
+// recover only stops a panic when called directly by the deferred function, which is why it is not called
+// by RecoverCaller itself. recovered is the value given; ok is false if there was nothing recovered, or the
+// panicking frame could not be found.
+func (c ACaller) RecoverCaller(recovered interface{}) (frame runtime.Frame, _ interface{}, ok bool) {
+	if recovered == nil {
+		return frame, nil, false
+	}
+	var (
+		search   = frameSearch{c: &c, accept: func(runtime.Frame) bool { return true }}
+		panicked bool
+	)
+	// walk passed runtime.gopanic; the frames after it are the ones that lead to the panic.
+	c.walkStack(4, func(frame runtime.Frame) bool {
+		if !panicked {
+			panicked = frame.Function == "runtime.gopanic"
+			return true
+		}
+		return search.next(frame)
+	})
+	return search.frame, recovered, search.ok
+}
+
+// RecoverCaller finds the function that panicked, skipping any frames in the ignore lists. See ACaller.RecoverCaller
+func RecoverCaller(recovered interface{}) (frame runtime.Frame, _ interface{}, ok bool) {
+	return defaultCaller.RecoverCaller(recovered)
+}
+
+// Copyright 2021 Gautam Dey. All rights reserved.
+// Use of this source code is governed by the MIT
+// license that can be found in the LICENSE FILE
@@ -0,0 +1,47 @@
+package caller_test
+
+import (
+	"runtime"
+	"testing"
+
+	"github.com/gdey/caller"
+)
+
+func panicker() { panic("boom") }
+
+func TestACaller_RecoverCaller(t *testing.T) {
+	const expectedName = "github.com/gdey/caller_test.panicker"
+	var (
+		c         caller.ACaller
+		frame     runtime.Frame
+		callFrame runtime.Frame
+		recovered interface{}
+		ok        bool
+	)
+	func() {
+		defer func() {
+			callFrame = c.Caller()
+			frame, recovered, ok = c.RecoverCaller(recover())
+		}()
+		panicker()
+	}()
+	if !ok {
+		t.Fatalf("ok, expected true got false")
+	}
+	if recovered != "boom" {
+		t.Errorf("recovered expected 'boom' got '%v'", recovered)
+	}
+	if frame.Function != expectedName {
+		t.Errorf("frame expected '%v' got '%v'", expectedName, frame.Function)
+	}
+	if callFrame.Function != expectedName {
+		t.Errorf("caller frame expected '%v' got '%v'", expectedName, callFrame.Function)
+	}
+
+	t.Run("not panicking", func(t *testing.T) {
+		frame, recovered, ok := c.RecoverCaller(recover())
+		if ok || recovered != nil {
+			t.Errorf("ok, expected false got true; frame %v recovered %v", frame.Function, recovered)
+		}
+	})
+}
@@ -51,3 +51,46 @@ func HelperPackage() runtime.Frame {
 	c.IgnorePackage()
 	return c.Double()
 }
+
+func (c MyCaller) tree() string { return c.CallerTree() }
+
+// Tree returns the caller tree as seen from this package.
+func Tree() string {
+	var c MyCaller
+	return c.tree()
+}
+
+func (c MyCaller) functions() []string { return c.StackFunctions() }
+
+// Functions returns the stack functions as seen from this package.
+func Functions() []string {
+	var c MyCaller
+	return c.functions()
+}
+
+func (c MyCaller) callers() []runtime.Frame { return c.Callers() }
+
+// Callers returns the callers as seen from this package.
+func Callers() []runtime.Frame {
+	var c MyCaller
+	return c.callers()
+}
+
+// Through calls fn; so that the frames of this package end up in the middle of the stack.
+func Through(fn func() []runtime.Frame) []runtime.Frame { return fn() }
+
+// WithTests returns a caller that ignores this package, and it's test variants.
+func WithTests() MyCaller {
+	var c MyCaller
+	c.IgnorePackageWithTests()
+	return c
+}
+
+func (c MyCaller) boundaryInner() (runtime.Frame, bool) { return c.CallerAcrossPackageBoundary() }
+func (c MyCaller) boundaryOuter() (runtime.Frame, bool) { return c.boundaryInner() }
+
+// Boundary returns the caller from outside of this package.
+func Boundary() (runtime.Frame, bool) {
+	var c MyCaller
+	return c.boundaryOuter()
+}
@@ -0,0 +1,51 @@
+//go:build go1.21
+// +build go1.21
+
+package caller
+
+// This file contains the helpers that describe the caller as log/slog attributes.
+
+import (
+	"log/slog"
+	"runtime"
+)
+
+// The keys of the caller attributes; they are the same as the keys slog uses for the source of a record.
+const (
+	SlogFunctionKey = "function"
+	SlogFileKey     = "file"
+	SlogLineKey     = "line"
+)
+
+// frameAttrs returns the frame as the attributes of a slog source; function, file and line.
+func frameAttrs(frame runtime.Frame) []slog.Attr {
+	return []slog.Attr{
+		slog.String(SlogFunctionKey, frame.Function),
+		slog.String(SlogFileKey, frame.File),
+		slog.Int(SlogLineKey, frame.Line),
+	}
+}
+
+// CallerAttrs returns the caller as log/slog attributes; function, file and line. nil is returned if the caller
+// could not be found.
+func (c ACaller) CallerAttrs() []slog.Attr {
+	frame, ok := c.caller(5)
+	if !ok {
+		return nil
+	}
+	return frameAttrs(frame)
+}
+
+// CallerGroup is like CallerAttrs, but returns the attributes as a single group under key; such as a caller group.
+// The group is empty if the caller could not be found, which slog handlers leave out.
+func (c ACaller) CallerGroup(key string) slog.Attr {
+	frame, ok := c.caller(5)
+	if !ok {
+		return slog.Attr{Key: key, Value: slog.GroupValue()}
+	}
+	return slog.Attr{Key: key, Value: slog.GroupValue(frameAttrs(frame)...)}
+}
+
+// Copyright 2021 Gautam Dey. All rights reserved.
+// Use of this source code is governed by the MIT
+// license that can be found in the LICENSE FILE
@@ -0,0 +1,84 @@
+//go:build go1.21
+// +build go1.21
+
+package caller_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/gdey/caller"
+)
+
+func recordPCOf(c caller.ACaller) uintptr { return c.CallerRecordPC() }
+
+func TestACaller_CallerRecordPC(t *testing.T) {
+	const expectedName = "github.com/gdey/caller_test.TestACaller_CallerRecordPC"
+	var c caller.ACaller
+	_, file, line, _ := runtime.Caller(0)
+	pc := recordPCOf(c)
+	line++
+	if pc == 0 {
+		t.Fatalf("pc, expected a pc got 0")
+	}
+
+	var buff bytes.Buffer
+	handler := slog.NewJSONHandler(&buff, &slog.HandlerOptions{AddSource: true})
+	record := slog.NewRecord(time.Now(), slog.LevelInfo, "message", pc)
+	if err := handler.Handle(context.Background(), record); err != nil {
+		t.Fatalf("handle, expected nil got %v", err)
+	}
+	var entry struct {
+		Source slog.Source `json:"source"`
+	}
+	if err := json.Unmarshal(buff.Bytes(), &entry); err != nil {
+		t.Fatalf("unmarshal, expected nil got %v", err)
+	}
+	if entry.Source.Function != expectedName {
+		t.Errorf("function expected '%v' got '%v'", expectedName, entry.Source.Function)
+	}
+	if entry.Source.File != file || entry.Source.Line != line {
+		t.Errorf("source expected '%v:%v' got '%v:%v'", file, line, entry.Source.File, entry.Source.Line)
+	}
+}
+
+func callerGroupOf(c caller.ACaller, key string) slog.Attr { return c.CallerGroup(key) }
+
+func TestACaller_CallerGroup(t *testing.T) {
+	const expectedName = "github.com/gdey/caller_test.TestACaller_CallerGroup"
+	var c caller.ACaller
+	_, file, line, _ := runtime.Caller(0)
+	attr := callerGroupOf(c, "caller")
+	line++
+	if attr.Key != "caller" {
+		t.Errorf("key expected 'caller' got '%v'", attr.Key)
+	}
+	if attr.Value.Kind() != slog.KindGroup {
+		t.Fatalf("kind expected %v got %v", slog.KindGroup, attr.Value.Kind())
+	}
+	got := make(map[string]slog.Value)
+	for _, a := range attr.Value.Group() {
+		got[a.Key] = a.Value
+	}
+	if v := got[caller.SlogFunctionKey]; v.String() != expectedName {
+		t.Errorf("function expected '%v' got '%v'", expectedName, v)
+	}
+	if v := got[caller.SlogFileKey]; v.String() != file {
+		t.Errorf("file expected '%v' got '%v'", file, v)
+	}
+	if v := got[caller.SlogLineKey]; v.Kind() != slog.KindInt64 || v.Int64() != int64(line) {
+		t.Errorf("line expected %v got %v", line, v)
+	}
+
+	c.IgnorePackage()
+	done := make(chan slog.Attr)
+	go func() { done <- callerGroupOf(c, "caller") }()
+	if attr := <-done; len(attr.Value.Group()) != 0 {
+		t.Errorf("not found group, expected empty got %v", attr.Value)
+	}
+}
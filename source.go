@@ -0,0 +1,91 @@
+package caller
+
+// This file contains the helpers that read the source of the caller.
+
+import (
+	"bufio"
+	"io"
+	"os"
+	"runtime"
+	"strings"
+	"sync/atomic"
+)
+
+// sourceReadingDisabled is 1 if reading source files is disabled, see SetSourceReadingEnabled
+var sourceReadingDisabled int32
+
+// SetSourceReadingEnabled enables, or disables, the reading of source files by all callers. When disabled the
+// features that read source files do not access the file system, and report that they could not find the
+// source (ok is false). This is useful in locked-down environments. Reading source files is enabled by default.
+func SetSourceReadingEnabled(enabled bool) {
+	var disabled int32
+	if !enabled {
+		disabled = 1
+	}
+	atomic.StoreInt32(&sourceReadingDisabled, disabled)
+}
+
+// SourceReadingEnabled returns weather reading source files is enabled, see SetSourceReadingEnabled.
+func SourceReadingEnabled() bool { return atomic.LoadInt32(&sourceReadingDisabled) == 0 }
+
+// SetSourceReadLimit caps the number of bytes read from any source file. Source that is past the limit is reported
+// as not found. A limit of 0 or less removes the cap.
+func (c *ACaller) SetSourceReadLimit(bytes int64) { c.sourceReadLimit = bytes }
+
+// readSourceLine returns the text of the given line, starting at 1, of the file; reading no more then limit bytes
+// of the file if limit is greater then 0.
+func readSourceLine(file string, line int, limit int64) (text string, ok bool) {
+	if !SourceReadingEnabled() || file == "" || line <= 0 {
+		return "", false
+	}
+	f, err := os.Open(file)
+	if err != nil {
+		return "", false
+	}
+	defer f.Close()
+	var r io.Reader = f
+	if limit > 0 {
+		r = io.LimitReader(f, limit)
+	}
+	var (
+		scanner = bufio.NewScanner(r)
+		// read is the number of bytes read, including the line endings; which may be \r\n
+		read int64
+		// ended is weather the last line read ended with a new line
+		ended bool
+	)
+	scanner.Split(func(data []byte, atEOF bool) (advance int, token []byte, err error) {
+		advance, token, err = bufio.ScanLines(data, atEOF)
+		read += int64(advance)
+		ended = advance > 0 && data[advance-1] == '\n'
+		return advance, token, err
+	})
+	for i := 1; scanner.Scan(); i++ {
+		if i != line {
+			continue
+		}
+		if limit > 0 && !ended && read >= limit {
+			// the line was cut short by the limit
+			return "", false
+		}
+		return scanner.Text(), true
+	}
+	return "", false
+}
+
+// CallerSourceLine is like CallerOK, but returns the text of the caller's line in it's source file, with the
+// surrounding white space removed. ok is false if the caller, or it's source, could not be found; which is always
+// the case for a binary built with -trimpath, as the file names are not paths to the source. Reading the source is
+// limited by SetSourceReadLimit and SetSourceReadingEnabled.
+func (c ACaller) CallerSourceLine() (text string, ok bool) {
+	var frame runtime.Frame
+	if frame, ok = c.caller(5); !ok {
+		return "", false
+	}
+	text, ok = readSourceLine(frame.File, frame.Line, c.sourceReadLimit)
+	return strings.TrimSpace(text), ok
+}
+
+// Copyright 2021 Gautam Dey. All rights reserved.
+// Use of this source code is governed by the MIT
+// license that can be found in the LICENSE FILE
@@ -0,0 +1,42 @@
+package caller
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+)
+
+func TestReadSourceLine_limit(t *testing.T) {
+	tests := map[string]struct {
+		source string
+		line   int
+		limit  int64
+		text   string
+		ok     bool
+	}{
+		"no limit":           {source: "ab\ncd\nefgh\n", line: 3, text: "efgh", ok: true},
+		"within limit":       {source: "ab\ncd\nefgh\n", line: 3, limit: 11, text: "efgh", ok: true},
+		"cut short":          {source: "ab\ncd\nefgh\n", line: 3, limit: 8},
+		"crlf within limit":  {source: "ab\r\ncd\r\nefgh\r\n", line: 3, limit: 14, text: "efgh", ok: true},
+		"crlf cut short":     {source: "ab\r\ncd\r\nefgh\r\n", line: 3, limit: 10},
+		"crlf past the line": {source: "ab\r\ncd\r\nefgh\r\n", line: 2, limit: 8, text: "cd", ok: true},
+		"no final new line":  {source: "ab\ncd", line: 2, text: "cd", ok: true},
+	}
+	dir := t.TempDir()
+	for name, tc := range tests {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			file := filepath.Join(dir, name+".go")
+			if err := ioutil.WriteFile(file, []byte(tc.source), 0o600); err != nil {
+				t.Fatalf("write source: %v", err)
+			}
+			text, ok := readSourceLine(file, tc.line, tc.limit)
+			if ok != tc.ok {
+				t.Fatalf("ok, expected %v got %v", tc.ok, ok)
+			}
+			if text != tc.text {
+				t.Errorf("text expected '%v' got '%v'", tc.text, text)
+			}
+		})
+	}
+}
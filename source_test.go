@@ -0,0 +1,74 @@
+package caller_test
+
+import (
+	"os"
+	"runtime"
+	"testing"
+
+	"github.com/gdey/caller"
+)
+
+func sourceLineOf(c caller.ACaller) (string, bool) { return c.CallerSourceLine() }
+
+// skipWithoutSource skips the test if the source files can not be opened; such as for a -trimpath build.
+func skipWithoutSource(t *testing.T) {
+	t.Helper()
+	_, file, _, _ := runtime.Caller(0)
+	if _, err := os.Stat(file); err != nil {
+		t.Skipf("source file %v can not be opened: %v", file, err)
+	}
+}
+
+func TestACaller_CallerSourceLine(t *testing.T) {
+	skipWithoutSource(t)
+	const expected = "text, ok := sourceLineOf(c) // the source line"
+	var c caller.ACaller
+	text, ok := sourceLineOf(c) // the source line
+	if !ok {
+		t.Fatalf("ok, expected true got false")
+	}
+	if text != expected {
+		t.Errorf("text expected '%v' got '%v'", expected, text)
+	}
+}
+
+func TestSetSourceReadingEnabled(t *testing.T) {
+	skipWithoutSource(t)
+	var c caller.ACaller
+	defer caller.SetSourceReadingEnabled(true)
+
+	caller.SetSourceReadingEnabled(false)
+	if caller.SourceReadingEnabled() {
+		t.Errorf("enabled, expected false got true")
+	}
+	if text, ok := sourceLineOf(c); ok {
+		t.Errorf("disabled ok, expected false got true: %v", text)
+	}
+
+	caller.SetSourceReadingEnabled(true)
+	if _, ok := sourceLineOf(c); !ok {
+		t.Errorf("enabled ok, expected true got false")
+	}
+}
+
+func TestACaller_SetSourceReadLimit(t *testing.T) {
+	skipWithoutSource(t)
+	tests := map[string]struct {
+		limit int64
+		ok    bool
+	}{
+		"no limit":    {ok: true},
+		"large limit": {limit: 1 << 20, ok: true},
+		"small limit": {limit: 64},
+	}
+	for name, tc := range tests {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			var c caller.ACaller
+			c.SetSourceReadLimit(tc.limit)
+			if _, ok := sourceLineOf(c); ok != tc.ok {
+				t.Errorf("ok, expected %v got %v", tc.ok, ok)
+			}
+		})
+	}
+}
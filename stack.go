@@ -0,0 +1,270 @@
+package caller
+
+// This file contains the helpers that work on the whole of the stack, instead of just the first caller.
+
+import (
+	"io"
+	"runtime"
+	"strconv"
+	"strings"
+)
+
+// walkFrames calls fn, from the innermost to the outermost, with each frame on the stack that is not in the ignore
+// lists; until fn returns false. skip is passed to getFrames, and should account for the frames of this package.
+func (c ACaller) walkFrames(skip int, fn func(frame runtime.Frame) bool) {
+	// account for our own frame
+	c.walkStack(skip+1, func(frame runtime.Frame) bool {
+		return c.skipFrame(frame) || fn(frame)
+	})
+}
+
+// filteredFrames returns, from the innermost to the outermost, the frames on the stack that are not in the ignore
+// lists. skip is passed to getFrames, and should account for the frames of this package.
+func (c ACaller) filteredFrames(skip int) (filtered []runtime.Frame) {
+	c.walkFrames(skip+1, func(frame runtime.Frame) bool {
+		filtered = append(filtered, frame)
+		return true
+	})
+	return filtered
+}
+
+// WalkFrames calls fn with each frame on the stack, that is not in the ignore lists, until fn returns false.
+// The frames are walked starting with the caller (the frame Caller would return) going out to the outermost
+// frame. Frames for inlined functions are included, in the order they would have been called if they were not
+// inlined.
+func (c ACaller) WalkFrames(fn func(frame runtime.Frame) bool) { c.walkFrames(5, fn) }
+
+// callers returns the frames on the stack that are not in the ignore lists, up to the callers depth. skip is passed
+// to getFrames, and should account for the frames of this package.
+func (c ACaller) callers(skip int) (filtered []runtime.Frame) {
+	c.walkFrames(skip+1, func(frame runtime.Frame) bool {
+		filtered = append(filtered, frame)
+		return c.callersDepth <= 0 || len(filtered) < c.callersDepth
+	})
+	return filtered
+}
+
+// SetCallersDepth limits the number of frames returned by Callers to depth; 0 or less removes the limit.
+func (c *ACaller) SetCallersDepth(depth int) { c.callersDepth = depth }
+
+// Callers returns the frames on the stack that are not in the ignore lists, up to the depth set by
+// SetCallersDepth. The first frame is the caller (the frame Caller would return), and the last frame is the
+// outermost one; the same order as WalkFrames. This allows a filtered stack trace to be attached to errors and
+// log entries.
+func (c ACaller) Callers() []runtime.Frame { return c.callers(5) }
+
+// shortFunctionName returns the function name without the package name
+func shortFunctionName(fullFuncName string) string {
+	pkg := PackageName(fullFuncName)
+	if pkg == "" {
+		return fullFuncName
+	}
+	return fullFuncName[len(pkg)+1:]
+}
+
+// CallerTree will render the stack, minus the frames in the ignore lists, as an indented tree. Frames are grouped
+// by their package, with the outermost frame at the top and the innermost frame at the bottom. Each package is
+// nested under the package that called it.
+func (c ACaller) CallerTree() string {
+	var (
+		frames      = c.filteredFrames(5)
+		str         strings.Builder
+		depth       = -1
+		lastPackage string
+	)
+	for i := len(frames) - 1; i >= 0; i-- {
+		pkg := PackageName(frames[i].Function)
+		if depth == -1 || pkg != lastPackage {
+			depth++
+			lastPackage = pkg
+			str.WriteString(strings.Repeat("  ", depth))
+			str.WriteString(pkg)
+			str.WriteString("\n")
+		}
+		str.WriteString(strings.Repeat("  ", depth+1))
+		str.WriteString(shortFunctionName(frames[i].Function))
+		str.WriteString("\n")
+	}
+	return str.String()
+}
+
+// StackFunctions returns the names, without the package name, of the functions on the stack that are not in the
+// ignore lists. The names are ordered from the innermost to the outermost frame, and a function is only listed
+// the first time it is seen.
+func (c ACaller) StackFunctions() []string {
+	var (
+		frames = c.filteredFrames(5)
+		names  = make([]string, 0, len(frames))
+		seen   = make(map[string]struct{}, len(frames))
+	)
+	for _, frame := range frames {
+		if _, ok := seen[frame.Function]; ok {
+			continue
+		}
+		seen[frame.Function] = struct{}{}
+		names = append(names, shortFunctionName(frame.Function))
+	}
+	return names
+}
+
+// DominantPackage returns the package that has the most frames, not in the ignore lists, on the stack and the
+// number of frames it has. If packages have the same number of frames, the one closest to the top of the stack is
+// returned. ok is false if there are no frames outside of the ignore lists.
+func (c ACaller) DominantPackage() (pkg string, count int, ok bool) {
+	var (
+		frames = c.filteredFrames(5)
+		counts = make(map[string]int, len(frames))
+		order  []string
+	)
+	for _, frame := range frames {
+		name := PackageName(frame.Function)
+		if _, seen := counts[name]; !seen {
+			order = append(order, name)
+		}
+		counts[name]++
+	}
+	for _, name := range order {
+		if counts[name] > count {
+			pkg, count = name, counts[name]
+		}
+	}
+	return pkg, count, count != 0
+}
+
+// PathBetween returns the frames, not in the ignore lists, on the stack from the function named fromFullName up to
+// the function named toFullName, inclusive. The frames are ordered from the innermost to the outermost; so fromFullName
+// should be the function lower on the stack. ok is false if either function is not on the stack, or they are in
+// the wrong order. The nearest occurrence of each function is used.
+func (c ACaller) PathBetween(fromFullName, toFullName string) (path []runtime.Frame, ok bool) {
+	fromFullName, toFullName = c.canonicalName(fromFullName), c.canonicalName(toFullName)
+	frames := c.filteredFrames(5)
+	for i := range frames {
+		if c.canonicalName(frames[i].Function) != fromFullName {
+			continue
+		}
+		for j := i; j < len(frames); j++ {
+			if c.canonicalName(frames[j].Function) == toFullName {
+				return frames[i : j+1], true
+			}
+		}
+		return nil, false
+	}
+	return nil, false
+}
+
+// CallerRecursionDepth returns the number of times the caller's function appears, one after the other, at the top of
+// the stack; not including frames in the ignore lists. A caller that is not recursing has a depth of 1, and 0 is
+// returned if there is no caller.
+func (c ACaller) CallerRecursionDepth() (depth int) {
+	var function string
+	c.walkFrames(5, func(frame runtime.Frame) bool {
+		if depth != 0 && frame.Function != function {
+			return false
+		}
+		function = frame.Function
+		depth++
+		return true
+	})
+	return depth
+}
+
+// CallerOutsideRecursion is like CallerOK, but if the caller is recursing, it will return the frame that first
+// called into the recursion. That is, the first frame, not in the ignore lists, that is not the caller's function.
+// A caller that is not recursing, with a depth of 1 (see CallerRecursionDepth), is returned as is.
+func (c ACaller) CallerOutsideRecursion() (frame runtime.Frame, ok bool) {
+	var (
+		first runtime.Frame
+		depth int
+	)
+	c.walkFrames(5, func(f runtime.Frame) bool {
+		if depth != 0 && f.Function != first.Function {
+			if depth == 1 {
+				// not recursing
+				f = first
+			}
+			frame, ok = f, true
+			return false
+		}
+		if depth == 0 {
+			first = f
+		}
+		depth++
+		return true
+	})
+	if !ok && depth == 1 {
+		// the caller is the last frame on the stack
+		return first, true
+	}
+	return frame, ok
+}
+
+// DetectReentry returns weather the function named selfFullName appears more then once on the stack, not counting
+// frames in the ignore lists; the function calling DetectReentry is counted. A logging function can use this to
+// detect that it has been called from within itself, and break the recursion.
+func (c ACaller) DetectReentry(selfFullName string) (reentered bool) {
+	var (
+		name  = c.canonicalName(selfFullName)
+		count int
+	)
+	// include the function calling DetectReentry
+	c.walkFrames(4, func(frame runtime.Frame) bool {
+		if c.canonicalName(frame.Function) == name {
+			count++
+		}
+		reentered = count > 1
+		return !reentered
+	})
+	return reentered
+}
+
+// FormatStack writes the stack, minus the frames in the ignore lists, to w in the form of a goroutine's stack in
+// a Go panic trace; the function followed by it's file and line, for each frame from the innermost to the outermost.
+func (c ACaller) FormatStack(w io.Writer) error {
+	_, err := io.WriteString(w, formatFrames(c.filteredFrames(5)))
+	return err
+}
+
+// formatFrames returns the frames in the form written by FormatStack.
+func formatFrames(frames []runtime.Frame) string {
+	var str strings.Builder
+	for _, frame := range frames {
+		str.WriteString(frame.Function)
+		str.WriteString("(...)\n\t")
+		str.WriteString(frame.File)
+		str.WriteString(":")
+		str.WriteString(strconv.Itoa(frame.Line))
+		str.WriteString("\n")
+	}
+	return str.String()
+}
+
+// FrameExplanation is the decision made about a frame on the stack, and why.
+type FrameExplanation struct {
+	Frame runtime.Frame
+	// Skipped is true if the frame is skipped when looking for the caller
+	Skipped bool
+	// Rule is the rule that decided to skip, or keep, the frame
+	Rule SkipRule
+	// Entry is the entry in the ignore lists, or the allowed modules, that matched the frame; if any
+	Entry string
+}
+
+// ExplainStack returns, for every frame on the stack starting with the frame Caller would start with, the
+// decision made about the frame and the rule that made it. This helps with tuning the ignore lists.
+func (c ACaller) ExplainStack() (explanations []FrameExplanation) {
+	c.walkStack(5, func(frame runtime.Frame) bool {
+		skipped, rule, entry := c.skipRule(frame)
+		explanations = append(explanations, FrameExplanation{
+			Frame:   frame,
+			Skipped: skipped,
+			Rule:    rule,
+			Entry:   entry,
+		})
+		return true
+	})
+	return explanations
+}
+
+// Copyright 2021 Gautam Dey. All rights reserved.
+// Use of this source code is governed by the MIT
+// license that can be found in the LICENSE FILE
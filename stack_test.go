@@ -0,0 +1,432 @@
+package caller_test
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"reflect"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/gdey/caller"
+	"github.com/gdey/caller/simple/log"
+)
+
+func TestACaller_CallerTree(t *testing.T) {
+	tree := func() string { return log.Tree() }()
+	const expected = `testing
+  tRunner
+  github.com/gdey/caller_test
+    TestACaller_CallerTree
+    TestACaller_CallerTree.func1
+    github.com/gdey/caller/simple/log
+      Tree
+`
+	if tree != expected {
+		t.Errorf("tree expected:\n%v\ngot:\n%v", expected, tree)
+	}
+}
+
+func recurseFunctions(n int) []string {
+	if n == 0 {
+		return log.Functions()
+	}
+	return recurseFunctions(n - 1)
+}
+
+func TestACaller_StackFunctions(t *testing.T) {
+	got := func() []string { return recurseFunctions(2) }()
+	expected := []string{
+		"Functions",
+		"recurseFunctions",
+		"TestACaller_StackFunctions.func1",
+		"TestACaller_StackFunctions",
+		"tRunner",
+	}
+	if !reflect.DeepEqual(got, expected) {
+		t.Errorf("functions expected %v got %v", expected, got)
+	}
+}
+
+func pathA(c caller.ACaller, from, to string) ([]runtime.Frame, bool) { return pathB(c, from, to) }
+func pathB(c caller.ACaller, from, to string) ([]runtime.Frame, bool) { return pathC(c, from, to) }
+func pathC(c caller.ACaller, from, to string) ([]runtime.Frame, bool) { return pathD(c, from, to) }
+func pathD(c caller.ACaller, from, to string) ([]runtime.Frame, bool) { return c.PathBetween(from, to) }
+
+// stripVendor removes the vendor directory from the name, so vendored packages have the same name as the originals
+func stripVendor(name string) string {
+	if idx := strings.LastIndex(name, "/vendor/"); idx != -1 {
+		return name[idx+len("/vendor/"):]
+	}
+	return name
+}
+
+func TestACaller_PathBetween(t *testing.T) {
+	const pkg = "github.com/gdey/caller_test."
+	tests := map[string]struct {
+		from, to     string
+		expected     []string
+		ok           bool
+		canonicalize func(string) string
+	}{
+		"chain": {
+			from:     pkg + "pathC",
+			to:       pkg + "pathA",
+			expected: []string{pkg + "pathC", pkg + "pathB", pkg + "pathA"},
+			ok:       true,
+		},
+		"same function": {
+			from:     pkg + "pathB",
+			to:       pkg + "pathB",
+			expected: []string{pkg + "pathB"},
+			ok:       true,
+		},
+		"wrong order":  {from: pkg + "pathA", to: pkg + "pathC"},
+		"missing from": {from: pkg + "pathZ", to: pkg + "pathA"},
+		"missing to":   {from: pkg + "pathC", to: pkg + "pathZ"},
+		"vendored names": {
+			from:         "example.com/app/vendor/" + pkg + "pathB",
+			to:           "example.com/app/vendor/" + pkg + "pathA",
+			expected:     []string{pkg + "pathB", pkg + "pathA"},
+			ok:           true,
+			canonicalize: stripVendor,
+		},
+	}
+	for name, tc := range tests {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			var c caller.ACaller
+			c.SetNameCanonicalizer(tc.canonicalize)
+			path, ok := pathA(c, tc.from, tc.to)
+			if ok != tc.ok {
+				t.Fatalf("ok, expected %v got %v", tc.ok, ok)
+			}
+			var names []string
+			for _, frame := range path {
+				names = append(names, frame.Function)
+			}
+			if !reflect.DeepEqual(names, tc.expected) {
+				t.Errorf("path expected %v got %v", tc.expected, names)
+			}
+		})
+	}
+}
+
+func TestACaller_Callers(t *testing.T) {
+	const pkg = "github.com/gdey/caller_test."
+	frames := log.Through(func() []runtime.Frame { return log.Callers() })
+	expected := []string{
+		"github.com/gdey/caller/simple/log.Callers",
+		pkg + "TestACaller_Callers.func1",
+		"github.com/gdey/caller/simple/log.Through",
+		pkg + "TestACaller_Callers",
+		"testing.tRunner",
+	}
+	var names []string
+	for _, frame := range frames {
+		names = append(names, frame.Function)
+	}
+	if !reflect.DeepEqual(names, expected) {
+		t.Errorf("callers expected %v got %v", expected, names)
+	}
+}
+
+func callersOf(c caller.ACaller) []runtime.Frame { return c.Callers() }
+
+func TestACaller_SetCallersDepth(t *testing.T) {
+	const pkg = "github.com/gdey/caller_test."
+	var c caller.ACaller
+	c.SetCallersDepth(2)
+	frames := func() []runtime.Frame { return callersOf(c) }()
+	expected := []string{pkg + "TestACaller_SetCallersDepth.func1", pkg + "TestACaller_SetCallersDepth"}
+	var names []string
+	for _, frame := range frames {
+		names = append(names, frame.Function)
+	}
+	if !reflect.DeepEqual(names, expected) {
+		t.Errorf("callers expected %v got %v", expected, names)
+	}
+	c.SetCallersDepth(0)
+	if frames := func() []runtime.Frame { return callersOf(c) }(); len(frames) <= 2 {
+		t.Errorf("callers without a depth, expected more then 2 frames got %v", len(frames))
+	}
+	// the depth is not limited by the number of frames to get
+	const depth = caller.DefaultNumberOfFramesToGet * 4
+	c.SetCallersDepth(depth)
+	if frames := callersRecurse(c, depth); len(frames) != depth {
+		t.Errorf("deep callers, expected %v frames got %v", depth, len(frames))
+	}
+}
+
+func callersRecurse(c caller.ACaller, depth int) []runtime.Frame {
+	if depth == 0 {
+		return callersOf(c)
+	}
+	return callersRecurse(c, depth-1)
+}
+
+func TestACaller_IgnorePackageNamed_pattern(t *testing.T) {
+	const pkg = "github.com/gdey/caller_test."
+	var c caller.ACaller
+	c.IgnorePackageNamed("github.com/gdey/caller/simple/*")
+	c.IgnorePackageNamed("testing")
+	frames := log.Through(func() []runtime.Frame { return callersOf(c) })
+	expected := []string{pkg + "TestACaller_IgnorePackageNamed_pattern.func1", pkg + "TestACaller_IgnorePackageNamed_pattern"}
+	var names []string
+	for _, frame := range frames {
+		names = append(names, frame.Function)
+	}
+	if !reflect.DeepEqual(names, expected) {
+		t.Errorf("callers expected %v got %v", expected, names)
+	}
+}
+
+func TestACaller_IgnorePackagePrefix(t *testing.T) {
+	const pkg = "github.com/gdey/caller_test."
+	var c caller.ACaller
+	c.IgnorePackagePrefix("github.com/gdey/caller/simple/")
+	c.IgnorePackagePrefix("testing")
+	frames := log.Through(func() []runtime.Frame { return callersOf(c) })
+	expected := []string{pkg + "TestACaller_IgnorePackagePrefix.func1", pkg + "TestACaller_IgnorePackagePrefix"}
+	var names []string
+	for _, frame := range frames {
+		names = append(names, frame.Function)
+	}
+	if !reflect.DeepEqual(names, expected) {
+		t.Errorf("callers expected %v got %v", expected, names)
+	}
+
+	// copies of the caller do not see each others prefixes
+	c.IgnorePackagePrefix("example.com/")
+	one, two := c, c
+	one.IgnorePackagePrefix("other.com/one/")
+	two.IgnorePackagePrefix("other.com/two/")
+	if frame := (runtime.Frame{Function: "other.com/one/log.Info"}); !one.IsIgnored(frame) || two.IsIgnored(frame) {
+		t.Errorf("copy prefix, expected %v ignored only by the first copy", frame.Function)
+	}
+	if frame := (runtime.Frame{Function: "other.com/two/log.Info"}); one.IsIgnored(frame) || !two.IsIgnored(frame) {
+		t.Errorf("copy prefix, expected %v ignored only by the second copy", frame.Function)
+	}
+
+	// the functions of the packages with an ignored prefix are not added
+	c.IgnorePackagePrefix("github.com/gdey/caller_test")
+	c.IgnoreFunction("callersOf")
+	c.IgnoreFunctionReason("example.com/app.Log", "logger")
+	if _, functions := c.IgnoreCounts(); functions != 0 {
+		t.Errorf("functions under an ignored prefix, expected 0 got %v: %v", functions, c.IgnoredFunctions())
+	}
+}
+
+func TestACaller_WalkFrames(t *testing.T) {
+	const pkg = "github.com/gdey/caller_test."
+	var (
+		c     caller.ACaller
+		names []string
+	)
+	func() {
+		c.WalkFrames(func(frame runtime.Frame) bool {
+			names = append(names, frame.Function)
+			// stop after the test function
+			return frame.Function != pkg+"TestACaller_WalkFrames"
+		})
+	}()
+	expected := []string{pkg + "TestACaller_WalkFrames"}
+	if !reflect.DeepEqual(names, expected) {
+		t.Errorf("frames expected %v got %v", expected, names)
+	}
+}
+
+func explainInner(c caller.ACaller) []caller.FrameExplanation { return c.ExplainStack() }
+func explainOuter(c caller.ACaller) []caller.FrameExplanation { return explainInner(c) }
+
+func TestACaller_ExplainStack(t *testing.T) {
+	const pkg = "github.com/gdey/caller_test."
+	var c caller.ACaller
+	c.IgnoreFunction("explainOuter")
+	c.SetAllowedModules("github.com/gdey/")
+
+	type explanation struct {
+		function string
+		skipped  bool
+		rule     caller.SkipRule
+		entry    string
+	}
+	expected := []explanation{
+		{function: pkg + "explainOuter", skipped: true, rule: caller.RuleIgnoredFunction, entry: pkg + "explainOuter"},
+		{function: pkg + "TestACaller_ExplainStack"},
+		{function: "testing.tRunner", skipped: true, rule: caller.RuleNotAllowedModule},
+		{function: "runtime.goexit", skipped: true, rule: caller.RuleRuntime},
+	}
+	var got []explanation
+	for _, e := range explainOuter(c) {
+		got = append(got, explanation{
+			function: e.Frame.Function,
+			skipped:  e.Skipped,
+			rule:     e.Rule,
+			entry:    e.Entry,
+		})
+	}
+	if !reflect.DeepEqual(got, expected) {
+		t.Errorf("explanations expected %+v got %+v", expected, got)
+	}
+}
+
+func recursionDepthOf(c caller.ACaller) int { return c.CallerRecursionDepth() }
+
+func recurseDepth(c caller.ACaller, n int, depths []int) []int {
+	depths = append(depths, recursionDepthOf(c))
+	if n == 0 {
+		return depths
+	}
+	return recurseDepth(c, n-1, depths)
+}
+
+func TestACaller_CallerRecursionDepth(t *testing.T) {
+	var c caller.ACaller
+	depths := recurseDepth(c, 3, nil)
+	expected := []int{1, 2, 3, 4}
+	if !reflect.DeepEqual(depths, expected) {
+		t.Errorf("depths expected %v got %v", expected, depths)
+	}
+	if depth := recursionDepthOf(c); depth != 1 {
+		t.Errorf("depth expected 1 got %v", depth)
+	}
+}
+
+func outsideRecursionOf(c caller.ACaller) (runtime.Frame, bool) { return c.CallerOutsideRecursion() }
+
+func recurseOutside(c caller.ACaller, n int) (runtime.Frame, bool) {
+	if n == 0 {
+		return outsideRecursionOf(c)
+	}
+	return recurseOutside(c, n-1)
+}
+
+func TestACaller_CallerOutsideRecursion(t *testing.T) {
+	const pkg = "github.com/gdey/caller_test."
+	var c caller.ACaller
+	// a caller that is not recursing is returned as is
+	for n, expectedName := range map[int]string{
+		0: pkg + "recurseOutside",
+		1: pkg + "TestACaller_CallerOutsideRecursion",
+		3: pkg + "TestACaller_CallerOutsideRecursion",
+	} {
+		frame, ok := recurseOutside(c, n)
+		if !ok {
+			t.Fatalf("ok for %v, expected true got false", n)
+		}
+		if frame.Function != expectedName {
+			t.Errorf("frame for %v expected '%v' got '%v'", n, expectedName, frame.Function)
+		}
+	}
+}
+
+func formatStackOf(c caller.ACaller, w io.Writer) error { return c.FormatStack(w) }
+
+func TestACaller_FormatStack(t *testing.T) {
+	var (
+		c    caller.ACaller
+		buff bytes.Buffer
+	)
+	c.IgnorePackageNamed("testing")
+	_, file, line, _ := runtime.Caller(0)
+	if err := formatStackOf(c, &buff); err != nil {
+		t.Fatalf("error, expected nil got %v", err)
+	}
+	expected := fmt.Sprintf("github.com/gdey/caller_test.TestACaller_FormatStack(...)\n\t%v:%v\n", file, line+1)
+	if got := buff.String(); got != expected {
+		t.Errorf("stack expected:\n%v\ngot:\n%v", expected, got)
+	}
+}
+
+func TestACaller_SetIncludeSelf(t *testing.T) {
+	const walkFrames = "github.com/gdey/caller.ACaller.WalkFrames"
+	hasSelf := func(c caller.ACaller) (found bool) {
+		// the frames of WalkFrames are on the stack while fn is running
+		c.WalkFrames(func(runtime.Frame) bool {
+			for _, frame := range c.Callers() {
+				if frame.Function == walkFrames {
+					found = true
+				}
+			}
+			return false
+		})
+		return found
+	}
+	var c caller.ACaller
+	if hasSelf(c) {
+		t.Errorf("self frames, expected hidden got shown")
+	}
+	c.SetIncludeSelf(true)
+	if !hasSelf(c) {
+		t.Errorf("self frames, expected shown got hidden")
+	}
+}
+
+func dominantOf(c caller.ACaller) (string, int, bool) { return c.DominantPackage() }
+
+func dominantRecurse(c caller.ACaller, n int) (string, int, bool) {
+	if n == 0 {
+		return dominantOf(c)
+	}
+	return dominantRecurse(c, n-1)
+}
+
+func TestACaller_DominantPackage(t *testing.T) {
+	var c caller.ACaller
+	pkg, count, ok := dominantRecurse(c, 4)
+	if !ok {
+		t.Fatalf("ok, expected true got false")
+	}
+	if pkg != "github.com/gdey/caller_test" {
+		t.Errorf("package expected 'github.com/gdey/caller_test' got '%v'", pkg)
+	}
+	// the five frames of dominantRecurse and the test
+	if count != 6 {
+		t.Errorf("count expected 6 got %v", count)
+	}
+
+	c.IgnorePackage()
+	done := make(chan bool)
+	go func() {
+		_, _, ok := dominantOf(c)
+		done <- ok
+	}()
+	if <-done {
+		t.Errorf("all ignored ok, expected false got true")
+	}
+}
+
+// reentryValue logs when it is formatted, like a String method that logs
+type reentryValue struct {
+	c      caller.ACaller
+	result *[]bool
+}
+
+func (v reentryValue) String() string {
+	reentryLog(v.c, v, v.result)
+	return "value"
+}
+
+// reentryLog is the logging function; it formats the value, which logs again
+func reentryLog(c caller.ACaller, v fmt.Stringer, result *[]bool) {
+	reentered := c.DetectReentry("github.com/gdey/caller_test.reentryLog")
+	*result = append(*result, reentered)
+	if reentered {
+		return
+	}
+	_ = v.String()
+}
+
+func TestACaller_DetectReentry(t *testing.T) {
+	var (
+		c      caller.ACaller
+		result []bool
+	)
+	reentryLog(c, reentryValue{c: c, result: &result}, &result)
+	expected := []bool{false, true}
+	if !reflect.DeepEqual(result, expected) {
+		t.Errorf("reentered expected %v got %v", expected, result)
+	}
+}
@@ -0,0 +1,103 @@
+package caller
+
+// This file contains the captured form of the stack, which can be shipped to another process.
+
+import (
+	"encoding/binary"
+	"errors"
+	"runtime"
+)
+
+// traceVersion is the version of the binary encoding of a Trace
+const traceVersion = 1
+
+// ErrInvalidTrace is returned when the binary form of a Trace could not be decoded.
+var ErrInvalidTrace = errors.New("caller: invalid trace")
+
+// Trace is a captured stack, of the frames not in the ignore lists. The frames are ordered from the caller to the
+// outermost frame.
+type Trace struct {
+	Frames []runtime.Frame
+}
+
+// Trace captures the stack, minus the frames in the ignore lists; the same frames as Callers.
+func (c ACaller) Trace() Trace { return Trace{Frames: c.filteredFrames(5)} }
+
+// MarshalBinary encodes the function name, file and line of each of the frames. The program counters are not
+// encoded, as they are meaningless outside of the process that captured the trace.
+func (t Trace) MarshalBinary() ([]byte, error) {
+	var (
+		buf     = make([]byte, 0, 1+binary.MaxVarintLen64*(1+3*len(t.Frames)))
+		scratch [binary.MaxVarintLen64]byte
+	)
+	putUvarint := func(v uint64) {
+		n := binary.PutUvarint(scratch[:], v)
+		buf = append(buf, scratch[:n]...)
+	}
+	putString := func(str string) {
+		putUvarint(uint64(len(str)))
+		buf = append(buf, str...)
+	}
+	buf = append(buf, traceVersion)
+	putUvarint(uint64(len(t.Frames)))
+	for _, frame := range t.Frames {
+		putString(frame.Function)
+		putString(frame.File)
+		putUvarint(uint64(frame.Line))
+	}
+	return buf, nil
+}
+
+// UnmarshalBinary decodes a trace encoded by MarshalBinary. The frames only have the function name, file and line.
+// ErrInvalidTrace is returned if data is not a valid encoding of a trace.
+func (t *Trace) UnmarshalBinary(data []byte) error {
+	if len(data) == 0 || data[0] != traceVersion {
+		return ErrInvalidTrace
+	}
+	data = data[1:]
+	invalid := false
+	uvarint := func() uint64 {
+		v, n := binary.Uvarint(data)
+		if n <= 0 {
+			invalid = true
+			return 0
+		}
+		data = data[n:]
+		return v
+	}
+	str := func() string {
+		n := uvarint()
+		if invalid || n > uint64(len(data)) {
+			invalid = true
+			return ""
+		}
+		s := string(data[:n])
+		data = data[n:]
+		return s
+	}
+	count := uvarint()
+	// each frame takes at least three bytes
+	if invalid || count > uint64(len(data))/3 {
+		return ErrInvalidTrace
+	}
+	frames := make([]runtime.Frame, 0, count)
+	for i := uint64(0); i < count; i++ {
+		var frame runtime.Frame
+		frame.Function = str()
+		frame.File = str()
+		frame.Line = int(uvarint())
+		if invalid {
+			return ErrInvalidTrace
+		}
+		frames = append(frames, frame)
+	}
+	if len(data) != 0 {
+		return ErrInvalidTrace
+	}
+	t.Frames = frames
+	return nil
+}
+
+// Copyright 2021 Gautam Dey. All rights reserved.
+// Use of this source code is governed by the MIT
+// license that can be found in the LICENSE FILE
@@ -0,0 +1,72 @@
+package caller_test
+
+import (
+	"runtime"
+	"testing"
+
+	"github.com/gdey/caller"
+)
+
+// symbolized returns the parts of the frames that survive the encoding
+func symbolized(frames []runtime.Frame) []runtime.Frame {
+	symbols := make([]runtime.Frame, 0, len(frames))
+	for _, frame := range frames {
+		symbols = append(symbols, runtime.Frame{Function: frame.Function, File: frame.File, Line: frame.Line})
+	}
+	return symbols
+}
+
+func TestTrace_MarshalBinary(t *testing.T) {
+	var c caller.ACaller
+	tests := map[string]caller.Trace{
+		"empty":    {},
+		"captured": func() caller.Trace { return c.Trace() }(),
+		"frames": {Frames: []runtime.Frame{
+			{Function: "example.com/app.(*Log).Info", File: "/src/app/log.go", Line: 10},
+			{Function: "example.com/app.main", File: "/src/app/main.go", Line: 200},
+			{Function: "", File: "", Line: 0},
+		}},
+	}
+	for name, trace := range tests {
+		trace := trace
+		t.Run(name, func(t *testing.T) {
+			data, err := trace.MarshalBinary()
+			if err != nil {
+				t.Fatalf("marshal error, expected nil got %v", err)
+			}
+			var got caller.Trace
+			if err := got.UnmarshalBinary(data); err != nil {
+				t.Fatalf("unmarshal error, expected nil got %v", err)
+			}
+			expected := symbolized(trace.Frames)
+			if len(got.Frames) != len(expected) {
+				t.Fatalf("frames expected %v got %v", len(expected), len(got.Frames))
+			}
+			for i := range expected {
+				if got.Frames[i] != expected[i] {
+					t.Errorf("frame %v expected %+v got %+v", i, expected[i], got.Frames[i])
+				}
+			}
+		})
+	}
+	if len(tests["captured"].Frames) < 2 {
+		t.Errorf("captured frames, expected multiple got %v", len(tests["captured"].Frames))
+	}
+}
+
+func TestTrace_UnmarshalBinary_invalid(t *testing.T) {
+	trace := caller.Trace{Frames: []runtime.Frame{{Function: "example.com/app.main", File: "main.go", Line: 1}}}
+	data, _ := trace.MarshalBinary()
+	tests := map[string][]byte{
+		"empty":     nil,
+		"version":   append([]byte{0}, data[1:]...),
+		"truncated": data[:len(data)-2],
+		"trailing":  append(append([]byte(nil), data...), 0),
+	}
+	for name, data := range tests {
+		var got caller.Trace
+		if err := got.UnmarshalBinary(data); err != caller.ErrInvalidTrace {
+			t.Errorf("%v error, expected %v got %v", name, caller.ErrInvalidTrace, err)
+		}
+	}
+}
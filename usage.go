@@ -0,0 +1,143 @@
+package caller
+
+// This file contains the tracking of which ignore entries are being used.
+
+import (
+	"fmt"
+	"io"
+	"sync"
+	"time"
+)
+
+// DefaultUnusedIgnoreThreshold is the number of calls to Caller after which ignore entries that have never matched
+// a frame are reported. This value can be changed via the SetUnusedIgnoreThreshold function.
+const DefaultUnusedIgnoreThreshold = 100
+
+// ignoreUsage tracks how often the ignore entries match a frame. It is shared between copies of an ACaller, so
+// it is safe for concurrent use. All the methods are safe to call on a nil ignoreUsage.
+type ignoreUsage struct {
+	lock sync.Mutex
+	// w is where the warnings are written
+	w io.Writer
+	// threshold is the number of calls after which the warnings are written
+	threshold int
+	// calls is the number of calls made so far
+	calls int
+	// started is when the tracking started, as given by the caller's clock; the warnings say how long the entries
+	// went unused
+	started time.Time
+	// hits is the number of times, keyed by entry, each ignore entry matched a frame
+	hits map[string]int
+}
+
+// hit records that the ignore entry matched a frame.
+func (u *ignoreUsage) hit(entry string) {
+	if u == nil {
+		return
+	}
+	u.lock.Lock()
+	u.hits[entry]++
+	u.lock.Unlock()
+}
+
+// reset returns a new ignoreUsage, with the same writer and threshold, that has no calls or hits and was started
+// at started; copies of the ACaller sharing u keep the counts they have. nil is returned for a nil ignoreUsage.
+func (u *ignoreUsage) reset(started time.Time) *ignoreUsage {
+	if u == nil {
+		return nil
+	}
+	u.lock.Lock()
+	defer u.lock.Unlock()
+	return &ignoreUsage{
+		w:         u.w,
+		threshold: u.threshold,
+		started:   started,
+		hits:      make(map[string]int),
+	}
+}
+
+// copy returns a copy of u, with it's own counts; so the calls and hits of the copy, and the changes to it's
+// threshold, are not seen by u. nil is returned for a nil ignoreUsage.
+func (u *ignoreUsage) copy() *ignoreUsage {
+	if u == nil {
+		return nil
+	}
+	u.lock.Lock()
+	defer u.lock.Unlock()
+	hits := make(map[string]int, len(u.hits))
+	for entry, count := range u.hits {
+		hits[entry] = count
+	}
+	return &ignoreUsage{
+		w:         u.w,
+		threshold: u.threshold,
+		calls:     u.calls,
+		started:   u.started,
+		hits:      hits,
+	}
+}
+
+// call records a call to Caller; once the threshold is reached any of the ignore entries that have not
+// matched a frame are reported.
+func (u *ignoreUsage) call(c *ACaller) {
+	if u == nil {
+		return
+	}
+	u.lock.Lock()
+	defer u.lock.Unlock()
+	u.calls++
+	if u.calls != u.threshold {
+		return
+	}
+	elapsed := c.now().Sub(u.started)
+	for _, pkgName := range c.ignorePackages {
+		if u.hits[pkgName] == 0 {
+			fmt.Fprintf(u.w, "caller: ignored package %q did not match any frame in %d calls over %v\n", pkgName, u.calls, elapsed)
+		}
+	}
+	for _, prefix := range c.ignorePackagePrefixes {
+		if u.hits[prefix] == 0 {
+			fmt.Fprintf(u.w, "caller: ignored package prefix %q did not match any frame in %d calls over %v\n", prefix, u.calls, elapsed)
+		}
+	}
+	for _, fnName := range c.ignoreFunctions {
+		if u.hits[fnName] == 0 {
+			fmt.Fprintf(u.w, "caller: ignored function %q did not match any frame in %d calls over %v\n", fnName, u.calls, elapsed)
+		}
+	}
+}
+
+// SetWarnOnUnusedIgnores will write a warning to w, for each of the entries in the ignore lists that have not
+// matched a frame, once Caller has been called the threshold number of times (see SetUnusedIgnoreThreshold); along
+// with how long the entries have gone unused. This surfaces misspelled or stale entries. The counts are reset each time this is called; a nil w turns off the tracking.
+func (c *ACaller) SetWarnOnUnusedIgnores(w io.Writer) {
+	if w == nil {
+		c.usage = nil
+		return
+	}
+	threshold := DefaultUnusedIgnoreThreshold
+	if c.usage != nil {
+		threshold = c.usage.threshold
+	}
+	c.usage = &ignoreUsage{
+		w:         w,
+		threshold: threshold,
+		started:   c.now(),
+		hits:      make(map[string]int),
+	}
+}
+
+// SetUnusedIgnoreThreshold will change the number of calls to Caller after which the unused ignore entries are
+// reported. This has no effect unless SetWarnOnUnusedIgnores has been called.
+func (c *ACaller) SetUnusedIgnoreThreshold(calls int) {
+	if c.usage == nil || calls <= 0 {
+		return
+	}
+	c.usage.lock.Lock()
+	c.usage.threshold = calls
+	c.usage.lock.Unlock()
+}
+
+// Copyright 2021 Gautam Dey. All rights reserved.
+// Use of this source code is governed by the MIT
+// license that can be found in the LICENSE FILE
@@ -0,0 +1,40 @@
+package caller
+
+import (
+	"bytes"
+	"testing"
+	"time"
+)
+
+func TestIgnoreUsage_elapsed(t *testing.T) {
+	var (
+		c     ACaller
+		buf   bytes.Buffer
+		clock = fakeClock{t: time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC)}
+	)
+	c.setClockForTest(clock.now)
+	c.IgnorePackageNamed("example.com/unused")
+	c.SetWarnOnUnusedIgnores(&buf)
+	c.SetUnusedIgnoreThreshold(2)
+
+	c.usage.call(&c)
+	clock.advance(90 * time.Second)
+	c.usage.call(&c)
+	const expected = "caller: ignored package \"example.com/unused\" did not match any frame in 2 calls over 1m30s\n"
+	if got := buf.String(); got != expected {
+		t.Errorf("warning expected %q got %q", expected, got)
+	}
+
+	// the time is measured from the reset
+	buf.Reset()
+	clock.advance(time.Hour)
+	c.Reset()
+	c.IgnorePackageNamed("example.com/unused")
+	clock.advance(time.Minute)
+	c.usage.call(&c)
+	c.usage.call(&c)
+	const afterReset = "caller: ignored package \"example.com/unused\" did not match any frame in 2 calls over 1m0s\n"
+	if got := buf.String(); got != afterReset {
+		t.Errorf("warning after reset expected %q got %q", afterReset, got)
+	}
+}
@@ -0,0 +1,66 @@
+package caller_test
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/gdey/caller"
+)
+
+func TestACaller_SetWarnOnUnusedIgnores(t *testing.T) {
+	var (
+		c   caller.ACaller
+		buf bytes.Buffer
+	)
+	c.IgnoreFunction("canonicalHelper")
+	c.IgnoreFunction("canonicalHelperTypo")
+	c.SetWarnOnUnusedIgnores(&buf)
+	c.SetUnusedIgnoreThreshold(3)
+
+	for i := 0; i < 2; i++ {
+		canonicalHelper(c)
+	}
+	if buf.Len() != 0 {
+		t.Fatalf("warnings, expected none before the threshold got %v", buf.String())
+	}
+	canonicalHelper(c)
+	warnings := buf.String()
+	if !strings.Contains(warnings, `"github.com/gdey/caller_test.canonicalHelperTypo"`) {
+		t.Errorf("warnings, expected warning for canonicalHelperTypo got %v", warnings)
+	}
+	if strings.Contains(warnings, `"github.com/gdey/caller_test.canonicalHelper"`) {
+		t.Errorf("warnings, expected no warning for canonicalHelper got %v", warnings)
+	}
+	if count := strings.Count(warnings, "\n"); count != 1 {
+		t.Errorf("warnings, expected 1 got %v", count)
+	}
+	// the warnings are only written once
+	canonicalHelper(c)
+	if warnings != buf.String() {
+		t.Errorf("warnings, expected no new warnings got %v", buf.String())
+	}
+}
+
+func TestACaller_Reset_unusedIgnores(t *testing.T) {
+	var (
+		c   caller.ACaller
+		buf bytes.Buffer
+	)
+	c.SetWarnOnUnusedIgnores(&buf)
+	c.SetUnusedIgnoreThreshold(2)
+	c.IgnoreFunction("canonicalHelperTypo")
+	canonicalHelper(c)
+
+	// the calls before the reset are not counted
+	c.Reset()
+	c.IgnoreFunction("canonicalHelperTypo")
+	canonicalHelper(c)
+	if buf.Len() != 0 {
+		t.Fatalf("warnings, expected none before the threshold got %v", buf.String())
+	}
+	canonicalHelper(c)
+	if !strings.Contains(buf.String(), `"github.com/gdey/caller_test.canonicalHelperTypo"`) {
+		t.Errorf("warnings, expected warning for canonicalHelperTypo got %v", buf.String())
+	}
+}